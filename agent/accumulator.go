@@ -1,7 +1,6 @@
 package agent
 
 import (
-	"log"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -16,12 +15,16 @@ var (
 type MetricMaker interface {
 	LogName() string
 	MakeMetric(metric telegraf.Metric) telegraf.Metric
+	Log() telegraf.Logger
 }
 
 type accumulator struct {
 	maker     MetricMaker
 	metrics   chan<- telegraf.Metric
 	precision time.Duration
+
+	// now returns the time of metrics added without a timestamp.
+	now func() time.Time
 }
 
 func NewAccumulator(
@@ -32,6 +35,7 @@ func NewAccumulator(
 		maker:     maker,
 		metrics:   metrics,
 		precision: time.Nanosecond,
+		now:       time.Now,
 	}
 	return &acc
 }
@@ -111,7 +115,7 @@ func (ac *accumulator) AddError(err error) {
 		return
 	}
 	NErrors.Incr(1)
-	log.Printf("E! [%s] Error in plugin: %v", ac.maker.LogName(), err)
+	ac.maker.Log().Errorf("Error in plugin: %v", err)
 }
 
 func (ac *accumulator) SetPrecision(precision time.Duration) {
@@ -123,7 +127,7 @@ func (ac *accumulator) getTime(t []time.Time) time.Time {
 	if len(t) > 0 {
 		timestamp = t[0]
 	} else {
-		timestamp = time.Now()
+		timestamp = ac.now()
 	}
 	return timestamp.Round(ac.precision)
 }
@@ -0,0 +1,524 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+var (
+	durationType         = reflect.TypeOf(time.Duration(0))
+	internalDurationType = reflect.TypeOf(internal.Duration{})
+	internalSizeType     = reflect.TypeOf(internal.Size{})
+
+	promRe = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+	// dsnSecretRe matches the secrets of key value DSNs such as
+	// "host=localhost user=telegraf password=secret".
+	dsnSecretRe = regexp.MustCompile(`(?i)\b(password|passwd|pwd|sslpassword|secret|token)\s*=\s*('[^']*'|"[^"]*"|[^\s;]*)`)
+)
+
+// safeOptions are the options holding text shown as is in the configuration.
+// Other options holding text, such as strings, lists and maps, are redacted
+// as they may be secrets.
+var safeOptions = map[string]bool{
+	// Options common to all plugins.
+	"name":               true,
+	"alias":              true,
+	"name_override":      true,
+	"measurement_prefix": true,
+	"measurement_suffix": true,
+	"tags":               true,
+	"name_drop":          true,
+	"name_pass":          true,
+	"field_drop":         true,
+	"field_pass":         true,
+	"tag_exclude":        true,
+	"tag_include":        true,
+	"filter":             true,
+	"idempotency_tag":    true,
+	"level":              true,
+	"logfile":            true,
+	"log_format":         true,
+
+	// Options of the plugins.
+	"content_encoding":    true,
+	"data_format":         true,
+	"data_type":           true,
+	"database":            true,
+	"fields":              true,
+	"file":                true,
+	"files":               true,
+	"interfaces":          true,
+	"measurement":         true,
+	"method":              true,
+	"namespace":           true,
+	"path":                true,
+	"paths":               true,
+	"precision":           true,
+	"retention_policy":    true,
+	"tag_keys":            true,
+	"tls_allowed_cacerts": true,
+	"tls_ca":              true,
+	"tls_cert":            true,
+	"tls_key":             true,
+	"topic":               true,
+	"topics":              true,
+}
+
+// addressOptions are the options holding URLs, addresses or DSNs, shown
+// without their credentials.
+var addressOptions = map[string]bool{
+	"address":          true,
+	"addresses":        true,
+	"agents":           true,
+	"brokers":          true,
+	"data_source_name": true,
+	"dsn":              true,
+	"endpoint":         true,
+	"endpoints":        true,
+	"host":             true,
+	"hosts":            true,
+	"server":           true,
+	"servers":          true,
+	"service_address":  true,
+	"url":              true,
+	"urls":             true,
+}
+
+// agentStatus is the state of the agent returned by "GET /status".
+type agentStatus struct {
+	Inputs      []inputStatus  `json:"inputs"`
+	Outputs     []outputStatus `json:"outputs"`
+	Processors  []pluginStatus `json:"processors"`
+	Aggregators []pluginStatus `json:"aggregators"`
+}
+
+type pluginStatus struct {
+	Name         string      `json:"name"`
+	Alias        string      `json:"alias,omitempty"`
+	CommonConfig interface{} `json:"common_config"`
+	Config       interface{} `json:"config"`
+}
+
+type inputStatus struct {
+	pluginStatus
+	Paused             bool       `json:"paused"`
+	LastGather         *time.Time `json:"last_gather,omitempty"`
+	LastGatherDuration string     `json:"last_gather_duration,omitempty"`
+	LastError          string     `json:"last_error,omitempty"`
+	LastErrorTime      *time.Time `json:"last_error_time,omitempty"`
+}
+
+type outputStatus struct {
+	pluginStatus
+	Paused        bool         `json:"paused"`
+	BufferSize    int          `json:"buffer_size"`
+	BufferLimit   int          `json:"buffer_limit"`
+	BufferFill    float64      `json:"buffer_fill"`
+	LastWrite     *writeStatus `json:"last_write,omitempty"`
+	LastError     string       `json:"last_error,omitempty"`
+	LastErrorTime *time.Time   `json:"last_error_time,omitempty"`
+}
+
+type writeStatus struct {
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration"`
+	Metrics  int       `json:"metrics"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// statMetric is a selfstat metric returned by "GET /stats".
+type statMetric struct {
+	Name   string                 `json:"name"`
+	Tags   map[string]string      `json:"tags"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// configSnapshot is the configuration of the plugins, in the order of the
+// agent configuration.
+type configSnapshot struct {
+	inputs      []pluginStatus
+	outputs     []pluginStatus
+	processors  []pluginStatus
+	aggregators []pluginStatus
+}
+
+// snapshotConfig returns the configuration of the plugins.  It reads the
+// plugin structs so it must be taken before the plugins are started.
+func snapshotConfig(config *config.Config) configSnapshot {
+	var snapshot configSnapshot
+	for _, input := range config.Inputs {
+		snapshot.inputs = append(snapshot.inputs,
+			newPluginStatus(input.Config.Name, input.Config.Alias,
+				input.Config, input.Input))
+	}
+	for _, output := range config.Outputs {
+		snapshot.outputs = append(snapshot.outputs,
+			newPluginStatus(output.Config.Name, output.Config.Alias,
+				output.Config, output.Output))
+	}
+	for _, processor := range config.Processors {
+		snapshot.processors = append(snapshot.processors,
+			newPluginStatus(processor.Config.Name, processor.Config.Alias,
+				processor.Config, processor.Processor))
+	}
+	for _, aggregator := range config.Aggregators {
+		snapshot.aggregators = append(snapshot.aggregators,
+			newPluginStatus(aggregator.Config.Name, aggregator.Config.Alias,
+				aggregator.Config, aggregator.Aggregator))
+	}
+	return snapshot
+}
+
+// status returns the state of all plugins.
+func (c *controlServer) status() agentStatus {
+	status := agentStatus{
+		Inputs:      []inputStatus{},
+		Outputs:     []outputStatus{},
+		Processors:  []pluginStatus{},
+		Aggregators: []pluginStatus{},
+	}
+
+	for i, input := range c.config.Inputs {
+		s := inputStatus{
+			pluginStatus: c.configs.inputs[i],
+			Paused:       input.Paused(),
+		}
+		if t, elapsed := input.LastGather(); !t.IsZero() {
+			s.LastGather = &t
+			s.LastGatherDuration = elapsed.String()
+		}
+		if t, msg := input.LastError(); !t.IsZero() {
+			s.LastError = msg
+			s.LastErrorTime = &t
+		}
+		status.Inputs = append(status.Inputs, s)
+	}
+
+	for i, output := range c.config.Outputs {
+		s := outputStatus{
+			pluginStatus: c.configs.outputs[i],
+			Paused:       output.Paused(),
+			BufferSize:   output.BufferLen(),
+			BufferLimit:  output.MetricBufferLimit,
+		}
+		if s.BufferLimit > 0 {
+			s.BufferFill = float64(s.BufferSize) / float64(s.BufferLimit) * 100
+		}
+		if result := output.LastWrite(); !result.Time.IsZero() {
+			s.LastWrite = &writeStatus{
+				Time:     result.Time,
+				Duration: result.Duration.String(),
+				Metrics:  result.Metrics,
+			}
+			if result.Err != nil {
+				s.LastWrite.Error = result.Err.Error()
+			}
+		}
+		if t, msg := output.LastError(); !t.IsZero() {
+			s.LastError = msg
+			s.LastErrorTime = &t
+		}
+		status.Outputs = append(status.Outputs, s)
+	}
+
+	status.Processors = append(status.Processors, c.configs.processors...)
+	status.Aggregators = append(status.Aggregators, c.configs.aggregators...)
+
+	return status
+}
+
+func newPluginStatus(name, alias string, commonConfig, plugin interface{}) pluginStatus {
+	commonValue, _ := configValue(reflect.ValueOf(commonConfig))
+	pluginValue, _ := configValue(reflect.ValueOf(plugin))
+	return pluginStatus{
+		Name:         name,
+		Alias:        alias,
+		CommonConfig: commonValue,
+		Config:       pluginValue,
+	}
+}
+
+// configValue returns the resolved configuration of a plugin, using the
+// option names of the configuration file.  Text options are redacted unless
+// they are known to be safe, and values that cannot be configured, such as
+// interfaces and channels, are skipped.
+func configValue(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String(), true
+	case internalDurationType:
+		return v.Interface().(internal.Duration).Duration.String(), true
+	case internalSizeType:
+		return v.Interface().(internal.Size).Size, true
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, false
+		}
+		return configValue(v.Elem())
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		addStructFields(fields, v)
+		return fields, true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			if value, ok := configValue(v.MapIndex(key)); ok {
+				m[key.String()] = value
+			}
+		}
+		return m, true
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if value, ok := configValue(v.Index(i)); ok {
+				list = append(list, value)
+			}
+		}
+		return list, true
+	case reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return nil, false
+	default:
+		return v.Interface(), true
+	}
+}
+
+func addStructFields(fields map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		// Options of embedded structs are set on the plugin itself.
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addStructFields(fields, v.Field(i))
+			continue
+		}
+
+		name := internal.SnakeCase(field.Name)
+		if tag := strings.Split(field.Tag.Get("toml"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		value, ok := configValue(v.Field(i))
+		if !ok {
+			continue
+		}
+		switch {
+		case addressOptions[name]:
+			value = scrubAddresses(value)
+		case safeOptions[name] || !hasText(field.Type):
+		default:
+			zero := reflect.Zero(field.Type).Interface()
+			if !reflect.DeepEqual(v.Field(i).Interface(), zero) {
+				value = "<redacted>"
+			}
+		}
+		fields[name] = value
+	}
+}
+
+// hasText returns true if values of the type may hold text.  The fields of
+// structs are redacted by their own names.
+func hasText(t reflect.Type) bool {
+	switch t {
+	case durationType, internalDurationType, internalSizeType:
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Map:
+		return true
+	case reflect.Ptr:
+		return hasText(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() == reflect.Uint8 || hasText(t.Elem())
+	default:
+		return false
+	}
+}
+
+// scrubAddresses removes the credentials of an address or list of addresses.
+func scrubAddresses(value interface{}) interface{} {
+	switch value := value.(type) {
+	case string:
+		return scrubAddress(value)
+	case []interface{}:
+		scrubbed := make([]interface{}, 0, len(value))
+		for _, v := range value {
+			scrubbed = append(scrubbed, scrubAddresses(v))
+		}
+		return scrubbed
+	default:
+		return "<redacted>"
+	}
+}
+
+// scrubAddress returns the address without the user info and query values of
+// URLs, and without the credentials of DSNs.
+func scrubAddress(address string) string {
+	if u, err := url.Parse(address); err == nil && u.Scheme != "" && u.Host != "" {
+		u.User = nil
+		if u.RawQuery != "" {
+			query := u.Query()
+			for k := range query {
+				query[k] = []string{"redacted"}
+			}
+			u.RawQuery = query.Encode()
+		}
+		return u.String()
+	}
+
+	// DSNs such as "user:password@tcp(localhost:3306)/db".
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		address = address[i+1:]
+	}
+	return dsnSecretRe.ReplaceAllString(address, "${1}=<redacted>")
+}
+
+// serveStatus handles "GET /status".
+func (c *controlServer) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.status())
+}
+
+// serveStats handles "GET /stats", the selfstat metrics as JSON.
+func (c *controlServer) serveStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats := []statMetric{}
+	for _, m := range selfstatMetrics() {
+		stats = append(stats, statMetric{
+			Name:   m.Name(),
+			Tags:   m.Tags(),
+			Fields: m.Fields(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// serveMetrics handles "GET /metrics", the selfstat metrics in the Prometheus
+// text format.
+func (c *controlServer) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(prometheusText(selfstatMetrics()))
+}
+
+// serveTraces handles "GET /traces", the recorded stages of the traced
+// metrics, optionally of a single trace given by the trace_id parameter.
+func (c *controlServer) serveTraces(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.Traces(r.URL.Query().Get("trace_id")))
+}
+
+// selfstatMetrics returns the selfstat metrics sorted by name.  The snapshot
+// does not clear the timings and histograms reported by inputs.internal.
+func selfstatMetrics() []telegraf.Metric {
+	metrics := selfstat.Snapshot()
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Name() < metrics[j].Name()
+	})
+	return metrics
+}
+
+// prometheusText returns the metrics in the Prometheus text format, each field
+// is a separate untyped metric named after the measurement and field.
+func prometheusText(metrics []telegraf.Metric) []byte {
+	samples := make(map[string][]string)
+	for _, m := range metrics {
+		var labels []string
+		for _, tag := range m.TagList() {
+			value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(tag.Value)
+			labels = append(labels, fmt.Sprintf(`%s="%s"`, promRe.ReplaceAllString(tag.Key, "_"), value))
+		}
+
+		for _, field := range m.FieldList() {
+			name := promRe.ReplaceAllString(m.Name()+"_"+field.Key, "_")
+			var value float64
+			switch v := field.Value.(type) {
+			case int64:
+				value = float64(v)
+			case uint64:
+				value = float64(v)
+			case float64:
+				value = v
+			case bool:
+				if v {
+					value = 1
+				}
+			default:
+				continue
+			}
+
+			sample := name
+			if len(labels) > 0 {
+				sample += "{" + strings.Join(labels, ",") + "}"
+			}
+			samples[name] = append(samples[name], fmt.Sprintf("%s %v", sample, value))
+		}
+	}
+
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "# TYPE %s untyped\n", name)
+		sort.Strings(samples[name])
+		for _, sample := range samples[name] {
+			buf.WriteString(sample + "\n")
+		}
+	}
+	return buf.Bytes()
+}
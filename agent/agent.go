@@ -3,7 +3,9 @@ package agent
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"runtime"
 	"sync"
 	"time"
@@ -18,6 +20,8 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	cardinality *cardinalityGuard
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -45,21 +49,51 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
+	err = a.initCardinalityGuard()
+	if err != nil {
+		return err
+	}
+
+	a.checkDeliveryTimeouts()
+
 	log.Printf("D! [agent] Connecting outputs")
 	err = a.connectOutputs(ctx)
 	if err != nil {
 		return err
 	}
 
+	models.EnableTracing(a.Config.Agent.TraceSampleRate)
+	if models.TracingEnabled() {
+		log.Printf("I! [agent] Tracing %g of the metrics", a.Config.Agent.TraceSampleRate)
+	}
+
+	if a.Config.Agent.ControlAddress != "" {
+		control, err := newControlServer(a.Config.Agent.ControlAddress,
+			a.Config.Agent.ControlToken, a.Config)
+		if err != nil {
+			return fmt.Errorf("could not start control API: %v", err)
+		}
+		log.Printf("I! [agent] Serving control API on %s", a.Config.Agent.ControlAddress)
+		control.start()
+		defer control.stop()
+	}
+
 	inputC := make(chan telegraf.Metric, 100)
 	procC := make(chan telegraf.Metric, 100)
 	outputC := make(chan telegraf.Metric, 100)
 
 	startTime := time.Now()
 
+	log.Printf("D! [agent] Starting streaming processors")
+	stopProcessors, err := a.startStreamingProcessors(procC)
+	if err != nil {
+		return err
+	}
+
 	log.Printf("D! [agent] Starting service inputs")
 	err = a.startServiceInputs(ctx, inputC)
 	if err != nil {
+		stopProcessors()
 		return err
 	}
 
@@ -97,6 +131,10 @@ func (a *Agent) Run(ctx context.Context) error {
 			if err != nil {
 				log.Printf("E! [agent] Error running processors: %v", err)
 			}
+
+			log.Printf("D! [agent] Stopping streaming processors")
+			stopProcessors()
+
 			close(dst)
 			log.Printf("D! [agent] Processor channel closed")
 		}(src, dst)
@@ -145,10 +183,8 @@ func (a *Agent) Run(ctx context.Context) error {
 func (a *Agent) Test(ctx context.Context, waitDuration time.Duration) error {
 	var wg sync.WaitGroup
 	metricC := make(chan telegraf.Metric)
-	nulC := make(chan telegraf.Metric)
 	defer func() {
 		close(metricC)
-		close(nulC)
 		wg.Wait()
 	}()
 
@@ -167,6 +203,89 @@ func (a *Agent) Test(ctx context.Context, waitDuration time.Duration) error {
 		}
 	}()
 
+	return a.testInputs(ctx, waitDuration, metricC)
+}
+
+// TestPipeline runs the inputs once and passes the metrics through the
+// processors and through the aggregators over a single period.  For each
+// output it prints to stdout the data that would be written, without
+// connecting to the output.
+func (a *Agent) TestPipeline(ctx context.Context, waitDuration time.Duration) error {
+	var wg sync.WaitGroup
+	var metrics []telegraf.Metric
+	metricC := make(chan telegraf.Metric)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for metric := range metricC {
+			metrics = append(metrics, metric)
+		}
+	}()
+
+	startTime := time.Now()
+	err := a.testInputs(ctx, waitDuration, metricC)
+	close(metricC)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	processed, err := a.testProcessors(metrics)
+	if err != nil {
+		return err
+	}
+
+	return a.testOutputs(a.testAggregators(startTime, processed), os.Stdout)
+}
+
+// testProcessors applies the processors to the metrics and returns the
+// processed metrics, including the metrics emitted by the streaming
+// processors before they are stopped.
+func (a *Agent) testProcessors(metrics []telegraf.Metric) ([]telegraf.Metric, error) {
+	var wg sync.WaitGroup
+	var streamed []telegraf.Metric
+	streamC := make(chan telegraf.Metric, 100)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for metric := range streamC {
+			streamed = append(streamed, metric)
+		}
+	}()
+
+	stopProcessors, err := a.startStreamingProcessors(streamC)
+	if err != nil {
+		close(streamC)
+		wg.Wait()
+		return nil, err
+	}
+
+	var processed []telegraf.Metric
+	for _, metric := range metrics {
+		processed = append(processed, a.applyProcessors(metric)...)
+	}
+
+	stopProcessors()
+	close(streamC)
+	wg.Wait()
+	return append(processed, streamed...), nil
+}
+
+// testInputs runs the inputs once, sending their metrics to dst.
+func (a *Agent) testInputs(
+	ctx context.Context,
+	waitDuration time.Duration,
+	dst chan<- telegraf.Metric,
+) error {
+	var wg sync.WaitGroup
+	nulC := make(chan telegraf.Metric)
+	defer func() {
+		close(nulC)
+		wg.Wait()
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -190,7 +309,7 @@ func (a *Agent) Test(ctx context.Context, waitDuration time.Duration) error {
 
 	if hasServiceInputs {
 		log.Printf("D! [agent] Starting service inputs")
-		err := a.startServiceInputs(ctx, metricC)
+		err := a.startServiceInputs(ctx, dst)
 		if err != nil {
 			return err
 		}
@@ -204,7 +323,7 @@ func (a *Agent) Test(ctx context.Context, waitDuration time.Duration) error {
 			break
 		}
 
-		acc := NewAccumulator(input, metricC)
+		acc := NewAccumulator(input, dst)
 		acc.SetPrecision(a.Precision())
 
 		// Special instructions for some inputs. cpu, for example, needs to be
@@ -241,6 +360,99 @@ func (a *Agent) Test(ctx context.Context, waitDuration time.Duration) error {
 	return nil
 }
 
+// testAggregators adds the metrics to the aggregators over a single period
+// starting at startTime and returns the metrics passed on to the outputs: the
+// original metrics not dropped by an aggregator followed by the processed
+// aggregations.
+func (a *Agent) testAggregators(
+	startTime time.Time,
+	metrics []telegraf.Metric,
+) []telegraf.Metric {
+	if len(a.Config.Aggregators) == 0 {
+		return metrics
+	}
+
+	for _, agg := range a.Config.Aggregators {
+		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
+		agg.UpdateWindow(since, until)
+	}
+
+	var result []telegraf.Metric
+	for _, metric := range metrics {
+		var dropOriginal bool
+		for _, agg := range a.Config.Aggregators {
+			if ok := agg.Add(metric); ok {
+				dropOriginal = true
+			}
+		}
+
+		if !dropOriginal {
+			result = append(result, metric)
+		} else {
+			metric.Drop()
+		}
+	}
+
+	for _, agg := range a.Config.Aggregators {
+		result = append(result, a.pushAggregations(agg, time.Now)...)
+	}
+
+	return result
+}
+
+// pushAggregations pushes the aggregator once and returns the aggregations
+// after applying the processors other than the streaming processors.  Aggregations without a timestamp are given
+// the time returned by now.
+func (a *Agent) pushAggregations(
+	agg *models.RunningAggregator,
+	now func() time.Time,
+) []telegraf.Metric {
+	aggregations := make(chan telegraf.Metric, 100)
+	go func() {
+		acc := &accumulator{
+			maker:     agg,
+			metrics:   aggregations,
+			precision: a.Precision(),
+			now:       now,
+		}
+		agg.Push(acc)
+		close(aggregations)
+	}()
+
+	var metrics []telegraf.Metric
+	for metric := range aggregations {
+		metrics = append(metrics, a.applyAggregationProcessors(metric)...)
+	}
+	return metrics
+}
+
+// testOutputs writes to w the data each output would write for the metrics.
+func (a *Agent) testOutputs(metrics []telegraf.Metric, w io.Writer) error {
+	for _, output := range a.Config.Outputs {
+		batch := make([]telegraf.Metric, 0, len(metrics))
+		for _, metric := range metrics {
+			batch = append(batch, metric.Copy())
+		}
+
+		octets, err := output.DryRun(batch)
+		if err != nil {
+			return fmt.Errorf("could not serialize metrics for output %s: %v",
+				output.LogName(), err)
+		}
+
+		fmt.Fprintf(w, "> %s\n", output.LogName())
+		w.Write(octets)
+		if len(octets) > 0 && octets[len(octets)-1] != '\n' {
+			fmt.Fprintln(w)
+		}
+	}
+
+	for _, metric := range metrics {
+		metric.Reject()
+	}
+	return nil
+}
+
 // runInputs starts and triggers the periodic gather for Inputs.
 //
 // When the context is done the timers are stopped and this function returns
@@ -359,10 +571,79 @@ func (a *Agent) runProcessors(
 	return nil
 }
 
+// startStreamingProcessors starts the streaming processors, the metrics they
+// emit are applied to the following processors and sent to dst.  The
+// returned function stops them in order, so that the metrics flushed by a
+// processor still pass through the following ones.
+func (a *Agent) startStreamingProcessors(dst chan<- telegraf.Metric) (func(), error) {
+	var stops []func()
+	stopAll := func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+
+	for i, processor := range a.Config.Processors {
+		sp, ok := processor.Processor.(telegraf.StreamingProcessor)
+		if !ok {
+			continue
+		}
+
+		next := i + 1
+		emitted := make(chan telegraf.Metric, 100)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for metric := range emitted {
+				for _, metric := range a.applyProcessorsFrom(next, metric) {
+					dst <- metric
+				}
+			}
+		}()
+
+		err := sp.Start(NewAccumulator(processor, emitted))
+		if err != nil {
+			close(emitted)
+			<-done
+			stopAll()
+			return nil, fmt.Errorf("starting processor %s: %v", processor.LogName(), err)
+		}
+
+		stops = append(stops, func() {
+			sp.Stop()
+			close(emitted)
+			<-done
+		})
+	}
+	return stopAll, nil
+}
+
 // applyProcessors applies all processors to a metric.
 func (a *Agent) applyProcessors(m telegraf.Metric) []telegraf.Metric {
+	return a.applyProcessorsFrom(0, m)
+}
+
+// applyAggregationProcessors applies the processors to an aggregation,
+// except for the streaming processors: the metrics they emit are sent toward
+// the aggregators, which would aggregate the aggregation again.  This also
+// lets the aggregators push after the streaming processors are stopped.
+func (a *Agent) applyAggregationProcessors(m telegraf.Metric) []telegraf.Metric {
 	metrics := []telegraf.Metric{m}
 	for _, processor := range a.Config.Processors {
+		if _, ok := processor.Processor.(telegraf.StreamingProcessor); ok {
+			continue
+		}
+		metrics = processor.Apply(metrics...)
+	}
+
+	return metrics
+}
+
+// applyProcessorsFrom applies the processors starting at index first to a
+// metric.
+func (a *Agent) applyProcessorsFrom(first int, m telegraf.Metric) []telegraf.Metric {
+	metrics := []telegraf.Metric{m}
+	for _, processor := range a.Config.Processors[first:] {
 		metrics = processor.Apply(metrics...)
 	}
 
@@ -447,7 +728,7 @@ func (a *Agent) runAggregators(
 	}()
 
 	for metric := range aggregations {
-		metrics := a.applyProcessors(metric)
+		metrics := a.applyAggregationProcessors(metric)
 		for _, metric := range metrics {
 			dst <- metric
 		}
@@ -526,6 +807,13 @@ func (a *Agent) runOutputs(
 	}
 
 	for metric := range src {
+		if a.cardinality != nil {
+			metric = a.cardinality.apply(metric)
+			if metric == nil {
+				continue
+			}
+		}
+
 		for i, output := range a.Config.Outputs {
 			if i == len(a.Config.Outputs)-1 {
 				output.AddMetric(metric)
@@ -565,7 +853,7 @@ func (a *Agent) flush(
 		// Favor shutdown over other methods.
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, interval, output.Write))
+			logError(a.flushOnce(output, interval, output.Flush))
 			return
 		default:
 		}
@@ -582,7 +870,7 @@ func (a *Agent) flush(
 				logError(a.flushOnce(output, interval, output.WriteBatch))
 			}
 		case <-ctx.Done():
-			logError(a.flushOnce(output, interval, output.Write))
+			logError(a.flushOnce(output, interval, output.Flush))
 			return
 		}
 	}
@@ -617,6 +905,24 @@ func (a *Agent) flushOnce(
 
 }
 
+// initCardinalityGuard creates the cardinality guard if a limit is set.
+func (a *Agent) initCardinalityGuard() error {
+	if a.Config.Agent.CardinalityLimit <= 0 {
+		return nil
+	}
+
+	guard, err := newCardinalityGuard(
+		a.Config.Agent.CardinalityLimit,
+		a.Config.Agent.CardinalityMeasurementLimit,
+		a.Config.Agent.CardinalityAction,
+		a.Config.Agent.CardinalityResetInterval.Duration)
+	if err != nil {
+		return err
+	}
+	a.cardinality = guard
+	return nil
+}
+
 // initPlugins runs the Init function on plugins.
 func (a *Agent) initPlugins() error {
 	for _, input := range a.Config.Inputs {
@@ -650,6 +956,41 @@ func (a *Agent) initPlugins() error {
 	return nil
 }
 
+// deliveryWaiter is implemented by inputs holding their requests until the
+// metrics are written by the outputs.
+type deliveryWaiter interface {
+	// DeliveryWait returns the time a request waits for the delivery of its
+	// metrics, zero if requests do not wait.
+	DeliveryWait() time.Duration
+}
+
+// checkDeliveryTimeouts warns about inputs giving up on the delivery of their
+// metrics before an output flushes them.  Unless metric_batch_size metrics are
+// received in time, such requests fail with a 503 error and the metrics are
+// written twice when retried.
+func (a *Agent) checkDeliveryTimeouts() {
+	for _, input := range a.Config.Inputs {
+		waiter, ok := input.Input.(deliveryWaiter)
+		if !ok || waiter.DeliveryWait() <= 0 {
+			continue
+		}
+		timeout := waiter.DeliveryWait()
+
+		for _, output := range a.Config.Outputs {
+			interval := a.Config.Agent.FlushInterval.Duration
+			if output.Config.FlushInterval != 0 {
+				interval = output.Config.FlushInterval
+			}
+			if timeout < interval {
+				log.Printf("W! [agent] The delivery_timeout of [%s] (%s) is shorter than "+
+					"the flush_interval of [%s] (%s), requests will fail with a 503 error "+
+					"unless metric_batch_size metrics are received in time",
+					input.LogName(), timeout, output.LogName(), interval)
+			}
+		}
+	}
+}
+
 // connectOutputs connects to all outputs.
 func (a *Agent) connectOutputs(ctx context.Context) error {
 	for _, output := range a.Config.Outputs {
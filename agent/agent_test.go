@@ -1,12 +1,21 @@
 package agent
 
 import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/metric"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -166,3 +175,210 @@ func TestWindow(t *testing.T) {
 		})
 	}
 }
+
+type sumAggregator struct {
+	sum int64
+}
+
+func (s *sumAggregator) Description() string    { return "" }
+func (s *sumAggregator) SampleConfig() string   { return "" }
+func (s *sumAggregator) Reset()                 { s.sum = 0 }
+func (s *sumAggregator) Add(in telegraf.Metric) { s.sum += in.Fields()["value"].(int64) }
+func (s *sumAggregator) Push(acc telegraf.Accumulator) {
+	acc.AddFields("sum", map[string]interface{}{"value": s.sum}, map[string]string{})
+}
+
+func TestTestPipelineOutputs(t *testing.T) {
+	now := time.Now()
+	c := config.NewConfig()
+	c.Aggregators = []*models.RunningAggregator{
+		models.NewRunningAggregator(&sumAggregator{}, &models.AggregatorConfig{
+			Name:         "sum",
+			Period:       time.Minute,
+			DropOriginal: true,
+			Filter:       models.Filter{NamePass: []string{"cpu"}},
+		}),
+	}
+	filter := models.Filter{NamePass: []string{"sum"}}
+	require.NoError(t, filter.Compile())
+	require.NoError(t, c.Aggregators[0].Config.Filter.Compile())
+	c.Outputs = []*models.RunningOutput{
+		models.NewRunningOutput("file", &controlOutput{}, &models.OutputConfig{Name: "file"}, 0, 0),
+		models.NewRunningOutput("file", &controlOutput{},
+			&models.OutputConfig{Name: "file", Alias: "sums", Filter: filter}, 0, 0),
+	}
+	for _, output := range c.Outputs {
+		output.Serializer = influx.NewSerializer()
+	}
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	var metrics []telegraf.Metric
+	for _, name := range []string{"cpu", "cpu", "mem"} {
+		m, err := metric.New(name, map[string]string{}, map[string]interface{}{"value": int64(2)}, now)
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+
+	metrics = a.testAggregators(now, metrics)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "mem", metrics[0].Name())
+	assert.Equal(t, "sum", metrics[1].Name())
+	assert.Equal(t, map[string]interface{}{"value": int64(4)}, metrics[1].Fields())
+
+	var buf bytes.Buffer
+	require.NoError(t, a.testOutputs(metrics, &buf))
+	lines := strings.Split(buf.String(), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "> outputs.file", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "mem value=2i "))
+	assert.True(t, strings.HasPrefix(lines[2], "sum value=4i "))
+	assert.Equal(t, "> outputs.file::sums", lines[3])
+	assert.True(t, strings.HasPrefix(lines[4], "sum value=4i "))
+}
+
+// streamingProcessor tags the metrics and adds them to the accumulator from
+// a goroutine.
+type streamingProcessor struct {
+	acc     telegraf.Accumulator
+	metrics chan telegraf.Metric
+	done    chan struct{}
+}
+
+func (p *streamingProcessor) Description() string  { return "" }
+func (p *streamingProcessor) SampleConfig() string { return "" }
+func (p *streamingProcessor) Start(acc telegraf.Accumulator) error {
+	p.acc = acc
+	p.metrics = make(chan telegraf.Metric, 10)
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		for m := range p.metrics {
+			m.AddTag("streamed", "true")
+			p.acc.AddMetric(m)
+		}
+	}()
+	return nil
+}
+func (p *streamingProcessor) Stop() {
+	close(p.metrics)
+	<-p.done
+}
+func (p *streamingProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		p.metrics <- m
+	}
+	return nil
+}
+
+type tagProcessor struct{}
+
+func (p *tagProcessor) Description() string  { return "" }
+func (p *tagProcessor) SampleConfig() string { return "" }
+func (p *tagProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		m.AddTag("after", "true")
+	}
+	return in
+}
+
+func TestStreamingProcessors(t *testing.T) {
+	c := config.NewConfig()
+	c.Processors = []*models.RunningProcessor{
+		models.NewRunningProcessor(&streamingProcessor{}, &models.ProcessorConfig{Name: "streaming"}),
+		models.NewRunningProcessor(&tagProcessor{}, &models.ProcessorConfig{Name: "tag"}),
+	}
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	dst := make(chan telegraf.Metric, 10)
+	stop, err := a.startStreamingProcessors(dst)
+	require.NoError(t, err)
+
+	m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": int64(2)}, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, a.applyProcessors(m))
+
+	// The emitted metrics pass through the following processors.
+	stop()
+	require.Len(t, dst, 1)
+	assert.Equal(t, map[string]string{"streamed": "true", "after": "true"}, (<-dst).Tags())
+}
+
+func TestStreamingProcessorsAggregations(t *testing.T) {
+	now := time.Now()
+	c := config.NewConfig()
+	c.Processors = []*models.RunningProcessor{
+		models.NewRunningProcessor(&streamingProcessor{}, &models.ProcessorConfig{Name: "streaming"}),
+		models.NewRunningProcessor(&tagProcessor{}, &models.ProcessorConfig{Name: "tag"}),
+	}
+	c.Aggregators = []*models.RunningAggregator{
+		models.NewRunningAggregator(&sumAggregator{}, &models.AggregatorConfig{
+			Name:         "sum",
+			Period:       time.Minute,
+			DropOriginal: true,
+		}),
+	}
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	var metrics []telegraf.Metric
+	for i := 0; i < 2; i++ {
+		m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": int64(2)}, now)
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+
+	// The metrics emitted by the streaming processors are returned once
+	// they are stopped.
+	metrics, err = a.testProcessors(metrics)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	for _, m := range metrics {
+		assert.Equal(t, map[string]string{"streamed": "true", "after": "true"}, m.Tags())
+	}
+
+	// The aggregations skip the stopped streaming processors, instead of
+	// being sent back toward the aggregators.
+	metrics = a.testAggregators(now, metrics)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "sum", metrics[0].Name())
+	assert.Equal(t, map[string]string{"after": "true"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(4)}, metrics[0].Fields())
+}
+
+type waitingInput struct {
+	controlInput
+	wait time.Duration
+}
+
+func (i *waitingInput) DeliveryWait() time.Duration { return i.wait }
+
+func TestCheckDeliveryTimeouts(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	c := config.NewConfig()
+	c.Agent.FlushInterval = internal.Duration{Duration: 10 * time.Second}
+	c.Inputs = []*models.RunningInput{
+		models.NewRunningInput(&waitingInput{wait: 5 * time.Second}, &models.InputConfig{Name: "http_listener_v2"}),
+		models.NewRunningInput(&waitingInput{wait: 20 * time.Second}, &models.InputConfig{Name: "influxdb_listener"}),
+		models.NewRunningInput(&waitingInput{}, &models.InputConfig{Name: "http_listener_v2", Alias: "nowait"}),
+		models.NewRunningInput(&controlInput{}, &models.InputConfig{Name: "cpu"}),
+	}
+	c.Outputs = []*models.RunningOutput{
+		models.NewRunningOutput("file", &controlOutput{}, &models.OutputConfig{Name: "file"}, 0, 0),
+		models.NewRunningOutput("file", &controlOutput{},
+			&models.OutputConfig{Name: "file", Alias: "slow", FlushInterval: time.Minute}, 0, 0),
+	}
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	a.checkDeliveryTimeouts()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "[inputs.http_listener_v2] (5s) is shorter than the flush_interval of [outputs.file] (10s)")
+	assert.Contains(t, lines[1], "[inputs.http_listener_v2] (5s) is shorter than the flush_interval of [outputs.file::slow] (1m0s)")
+	assert.Contains(t, lines[2], "[inputs.influxdb_listener] (20s) is shorter than the flush_interval of [outputs.file::slow] (1m0s)")
+}
@@ -0,0 +1,261 @@
+package agent
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/hll"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Actions on the metrics of new series over the cardinality limit.
+const (
+	cardinalityDrop      = "drop"
+	cardinalityStripTags = "strip_tags"
+	cardinalityAlert     = "alert"
+)
+
+// defaultMeasurementLimit is the default maximum number of measurements
+// tracked by the cardinality guard.
+const defaultMeasurementLimit = 1000
+
+// cardinalityGuard limits the number of series of each measurement sent to
+// the outputs.  The series under the limit are tracked exactly so that their
+// metrics are always kept, the total number of series is estimated.
+//
+// The number of measurements tracked is limited as well, the action is
+// applied to all the metrics of the measurements over the limit.
+//
+// Not safe for concurrent use.
+type cardinalityGuard struct {
+	limit            int
+	measurementLimit int
+	action           string
+	resetInterval    time.Duration
+
+	now          func() time.Time
+	lastReset    time.Time
+	measurements map[string]*measurementSeries
+	// stats are the stats of the measurements tracked since the previous
+	// reset, kept over a reset.
+	stats map[string]*cardinalityStats
+	// overflowDropped counts the dropped metrics of the measurements over
+	// the limit.
+	overflowDropped selfstat.Stat
+	overflowWarned  bool
+}
+
+// measurementSeries is the series seen for a measurement.
+type measurementSeries struct {
+	*cardinalityStats
+
+	series map[uint64]bool
+	sketch *hll.Sketch
+	// tags estimates the number of values of each tag, for strip_tags.
+	tags   map[string]*tagValues
+	warned bool
+}
+
+type cardinalityStats struct {
+	estimate selfstat.Stat
+	dropped  selfstat.Stat
+	stripped selfstat.Stat
+}
+
+type tagValues struct {
+	sketch   *hll.Sketch
+	estimate uint64
+}
+
+func newCardinalityGuard(limit, measurementLimit int, action string, resetInterval time.Duration) (*cardinalityGuard, error) {
+	switch action {
+	case "":
+		action = cardinalityAlert
+	case cardinalityDrop, cardinalityStripTags, cardinalityAlert:
+	default:
+		return nil, fmt.Errorf("invalid cardinality_action %q", action)
+	}
+
+	if measurementLimit <= 0 {
+		measurementLimit = defaultMeasurementLimit
+	}
+
+	g := &cardinalityGuard{
+		limit:            limit,
+		measurementLimit: measurementLimit,
+		action:           action,
+		resetInterval:    resetInterval,
+		now:              time.Now,
+		stats:            make(map[string]*cardinalityStats),
+		overflowDropped:  selfstat.Register("cardinality", "metrics_dropped", map[string]string{}),
+	}
+	g.reset()
+	return g, nil
+}
+
+// apply counts the series of the metric and returns the metric, possibly
+// with tags removed, or nil if it is dropped.
+func (g *cardinalityGuard) apply(metric telegraf.Metric) telegraf.Metric {
+	if g.resetInterval > 0 && g.now().Sub(g.lastReset) >= g.resetInterval {
+		g.reset()
+	}
+
+	ms := g.measurement(metric.Name())
+	if ms == nil {
+		return g.applyOverflow(metric)
+	}
+
+	id := metric.HashID()
+	if ms.sketch.Insert(id) {
+		ms.estimate.Set(int64(ms.sketch.Estimate()))
+	}
+	if g.action == cardinalityStripTags {
+		ms.addTags(metric)
+	}
+
+	if ms.series[id] {
+		return metric
+	}
+	if len(ms.series) < g.limit {
+		ms.series[id] = true
+		return metric
+	}
+
+	if !ms.warned {
+		log.Printf("W! [agent] Measurement %q has more than %d series, "+
+			"applying cardinality_action %q to new series",
+			metric.Name(), g.limit, g.action)
+		ms.warned = true
+	}
+
+	switch g.action {
+	case cardinalityAlert:
+		return metric
+	case cardinalityStripTags:
+		if key := ms.mostValues(metric); key != "" {
+			metric.RemoveTag(key)
+			ms.stripped.Incr(1)
+			models.Tracef(metric, "agent", "tag %q removed, over the cardinality limit", key)
+
+			// The series left after removing the tag are kept up to
+			// twice the limit, bounding the memory used.
+			id = metric.HashID()
+			if ms.series[id] {
+				return metric
+			}
+			if len(ms.series) < 2*g.limit {
+				ms.series[id] = true
+				return metric
+			}
+		}
+	}
+
+	models.Trace(metric, "agent", "dropped, over the cardinality limit")
+	ms.dropped.Incr(1)
+	metric.Drop()
+	return nil
+}
+
+// applyOverflow applies the action to a metric of a measurement over the
+// limit.  Without the tag counts of the measurement, strip_tags drops the
+// metric.
+func (g *cardinalityGuard) applyOverflow(metric telegraf.Metric) telegraf.Metric {
+	if !g.overflowWarned {
+		log.Printf("W! [agent] More than %d measurements, applying "+
+			"cardinality_action %q to the metrics of measurement %q and "+
+			"further new measurements",
+			g.measurementLimit, g.action, metric.Name())
+		g.overflowWarned = true
+	}
+
+	if g.action == cardinalityAlert {
+		return metric
+	}
+
+	models.Trace(metric, "agent", "dropped, over the measurement limit")
+	g.overflowDropped.Incr(1)
+	metric.Drop()
+	return nil
+}
+
+// measurement returns the series of the measurement, nil if the measurement
+// is not tracked as there are too many measurements.
+func (g *cardinalityGuard) measurement(name string) *measurementSeries {
+	ms, ok := g.measurements[name]
+	if !ok {
+		if len(g.measurements) >= g.measurementLimit {
+			return nil
+		}
+
+		stats, ok := g.stats[name]
+		if !ok {
+			stats = newCardinalityStats(map[string]string{"measurement": name})
+			g.stats[name] = stats
+		}
+		ms = &measurementSeries{
+			cardinalityStats: stats,
+			series:           make(map[uint64]bool),
+			sketch:           hll.New(),
+			tags:             make(map[string]*tagValues),
+		}
+		ms.estimate.Set(0)
+		g.measurements[name] = ms
+	}
+	return ms
+}
+
+// reset forgets all series.  The counters of dropped metrics are kept for
+// the measurements seen since the previous reset, the stats of the others
+// are unregistered so that they do not accumulate.
+func (g *cardinalityGuard) reset() {
+	for name := range g.stats {
+		if _, ok := g.measurements[name]; !ok {
+			selfstat.Unregister("cardinality", map[string]string{"measurement": name})
+			delete(g.stats, name)
+		}
+	}
+	g.measurements = make(map[string]*measurementSeries)
+	g.overflowWarned = false
+	g.lastReset = g.now()
+}
+
+func newCardinalityStats(tags map[string]string) *cardinalityStats {
+	return &cardinalityStats{
+		estimate: selfstat.Register("cardinality", "series_estimate", tags),
+		dropped:  selfstat.Register("cardinality", "metrics_dropped", tags),
+		stripped: selfstat.Register("cardinality", "tags_stripped", tags),
+	}
+}
+
+func (ms *measurementSeries) addTags(metric telegraf.Metric) {
+	for _, tag := range metric.TagList() {
+		tv, ok := ms.tags[tag.Key]
+		if !ok {
+			tv = &tagValues{sketch: hll.New()}
+			ms.tags[tag.Key] = tv
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(tag.Value))
+		if tv.sketch.Insert(h.Sum64()) {
+			tv.estimate = tv.sketch.Estimate()
+		}
+	}
+}
+
+// mostValues returns the tag of the metric with the most values.
+func (ms *measurementSeries) mostValues(metric telegraf.Metric) string {
+	var key string
+	var most uint64
+	for _, tag := range metric.TagList() {
+		if tv, ok := ms.tags[tag.Key]; ok && tv.estimate > most {
+			key = tag.Key
+			most = tv.estimate
+		}
+	}
+	return key
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func cardinalityMetric(name, host, pid string) telegraf.Metric {
+	return testutil.MustMetric(name,
+		map[string]string{"host": host, "pid": pid},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0))
+}
+
+func TestCardinalityGuardInvalidAction(t *testing.T) {
+	_, err := newCardinalityGuard(10, 0, "explode", 0)
+	require.Error(t, err)
+}
+
+func TestCardinalityGuardDrop(t *testing.T) {
+	g, err := newCardinalityGuard(2, 0, "drop", 0)
+	require.NoError(t, err)
+
+	require.NotNil(t, g.apply(cardinalityMetric("drop_test", "a", "1")))
+	require.NotNil(t, g.apply(cardinalityMetric("drop_test", "a", "2")))
+	require.Nil(t, g.apply(cardinalityMetric("drop_test", "a", "3")))
+
+	// Known series are kept once over the limit.
+	require.NotNil(t, g.apply(cardinalityMetric("drop_test", "a", "1")))
+	// The limit applies to each measurement.
+	require.NotNil(t, g.apply(cardinalityMetric("drop_test_other", "a", "3")))
+
+	ms := g.measurements["drop_test"]
+	require.Equal(t, int64(1), ms.dropped.Get())
+	require.Equal(t, int64(3), ms.estimate.Get())
+}
+
+func TestCardinalityGuardStripTags(t *testing.T) {
+	g, err := newCardinalityGuard(4, 0, "strip_tags", 0)
+	require.NoError(t, err)
+
+	var kept []telegraf.Metric
+	for i := 0; i < 10; i++ {
+		host := fmt.Sprintf("host%d", i%2)
+		m := g.apply(cardinalityMetric("strip_test", host, fmt.Sprint(i)))
+		require.NotNil(t, m)
+		kept = append(kept, m)
+	}
+
+	// The pid tag has the most values and is removed once over the limit.
+	expected := []telegraf.Metric{
+		cardinalityMetric("strip_test", "host0", "0"),
+		cardinalityMetric("strip_test", "host1", "1"),
+		cardinalityMetric("strip_test", "host0", "2"),
+		cardinalityMetric("strip_test", "host1", "3"),
+	}
+	for i := 4; i < 10; i++ {
+		expected = append(expected, testutil.MustMetric("strip_test",
+			map[string]string{"host": fmt.Sprintf("host%d", i%2)},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0)))
+	}
+	testutil.RequireMetricsEqual(t, expected, kept)
+	require.Equal(t, int64(6), g.measurements["strip_test"].stripped.Get())
+}
+
+func TestCardinalityGuardAlert(t *testing.T) {
+	g, err := newCardinalityGuard(1, 0, "", 0)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NotNil(t, g.apply(cardinalityMetric("alert_test", "a", fmt.Sprint(i))))
+	}
+	ms := g.measurements["alert_test"]
+	require.True(t, ms.warned)
+	require.Equal(t, int64(0), ms.dropped.Get())
+	require.Equal(t, int64(5), ms.estimate.Get())
+}
+
+func TestCardinalityGuardReset(t *testing.T) {
+	now := time.Unix(0, 0)
+	g, err := newCardinalityGuard(1, 0, "drop", time.Hour)
+	require.NoError(t, err)
+	g.now = func() time.Time { return now }
+	g.reset()
+
+	require.NotNil(t, g.apply(cardinalityMetric("reset_test", "a", "1")))
+	require.Nil(t, g.apply(cardinalityMetric("reset_test", "a", "2")))
+
+	now = now.Add(time.Hour)
+	require.NotNil(t, g.apply(cardinalityMetric("reset_test", "a", "2")))
+}
+
+func TestCardinalityGuardMeasurementLimit(t *testing.T) {
+	now := time.Unix(0, 0)
+	g, err := newCardinalityGuard(10, 2, "drop", time.Hour)
+	require.NoError(t, err)
+	g.now = func() time.Time { return now }
+	g.reset()
+
+	require.NotNil(t, g.apply(cardinalityMetric("limit_test1", "a", "1")))
+	require.NotNil(t, g.apply(cardinalityMetric("limit_test2", "a", "1")))
+	// The metrics of further measurements are dropped.
+	require.Nil(t, g.apply(cardinalityMetric("limit_test3", "a", "1")))
+	require.NotNil(t, g.apply(cardinalityMetric("limit_test1", "a", "2")))
+	require.Len(t, g.measurements, 2)
+	require.Equal(t, int64(1), g.overflowDropped.Get())
+
+	// After a reset the stats of the measurements no longer seen are
+	// removed.
+	now = now.Add(time.Hour)
+	require.NotNil(t, g.apply(cardinalityMetric("limit_test3", "a", "1")))
+	now = now.Add(time.Hour)
+	require.NotNil(t, g.apply(cardinalityMetric("limit_test3", "a", "1")))
+	require.Len(t, g.stats, 1)
+	require.Contains(t, g.stats, "limit_test3")
+}
@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/config"
+)
+
+// pauser is implemented by the plugins that can be paused at runtime.
+type pauser interface {
+	Pause()
+	Resume()
+	Paused() bool
+}
+
+// pluginState is the state of a plugin returned by the control API.
+type pluginState struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Alias  string `json:"alias,omitempty"`
+	Paused bool   `json:"paused"`
+}
+
+// controlServer serves the control API used to pause and resume inputs and
+// outputs and to inspect the state of the agent.
+type controlServer struct {
+	config *config.Config
+	token  string
+
+	// configs is the configuration of the plugins taken when the server is
+	// created, as the plugin structs cannot be read safely while running.
+	configs configSnapshot
+
+	server   *http.Server
+	listener net.Listener
+}
+
+// newControlServer listens on the address, either "unix:///path/to/socket" or
+// "http://host:port".  Requests must authenticate with the token as a bearer
+// token when it is set, which is required on TCP addresses.
+func newControlServer(address, token string, config *config.Config) (*controlServer, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid control address %q: %v", address, err)
+	}
+
+	var listener net.Listener
+	switch u.Scheme {
+	case "unix":
+		if err := removeSocket(u.Path); err != nil {
+			return nil, err
+		}
+		listener, err = net.Listen("unix", u.Path)
+	case "http", "tcp":
+		// Unlike unix sockets, TCP addresses are not protected by file
+		// permissions.
+		if token == "" {
+			return nil, fmt.Errorf("control_token is required to serve the control API on %q", address)
+		}
+		listener, err = net.Listen("tcp", u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported control address scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &controlServer{
+		config:   config,
+		token:    token,
+		configs:  snapshotConfig(config),
+		listener: listener,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/plugins", c.serveList)
+	mux.HandleFunc("/plugins/", c.serveAction)
+	mux.HandleFunc("/status", c.serveStatus)
+	mux.HandleFunc("/stats", c.serveStats)
+	mux.HandleFunc("/metrics", c.serveMetrics)
+	mux.HandleFunc("/traces", c.serveTraces)
+	c.server = &http.Server{
+		Handler:      c.authenticate(mux),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	return c, nil
+}
+
+// removeSocket removes the socket left behind if telegraf was not stopped
+// cleanly, refusing to remove any other kind of file.
+func removeSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("control address %q is not a socket", path)
+	}
+	return os.Remove(path)
+}
+
+func (c *controlServer) start() {
+	go func() {
+		err := c.server.Serve(c.listener)
+		if err != nil && err != http.ErrServerClosed {
+			log.Printf("E! [agent] Error serving control API: %v", err)
+		}
+	}()
+}
+
+func (c *controlServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c.server.Shutdown(ctx)
+}
+
+// authenticate rejects the requests without the bearer token, if any.
+func (c *controlServer) authenticate(next http.Handler) http.Handler {
+	if c.token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + c.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(auth, expected) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// plugins returns the plugins of the type with the name or alias, or all
+// plugins when name is empty.
+func (c *controlServer) plugins(pluginType, name string) ([]pauser, []pluginState) {
+	var plugins []pauser
+	var states []pluginState
+
+	if pluginType == "" || pluginType == "inputs" {
+		for _, input := range c.config.Inputs {
+			if name == "" || name == input.Config.Name || name == input.Config.Alias {
+				plugins = append(plugins, input)
+				states = append(states, pluginState{
+					Type:  "inputs",
+					Name:  input.Config.Name,
+					Alias: input.Config.Alias,
+				})
+			}
+		}
+	}
+
+	if pluginType == "" || pluginType == "outputs" {
+		for _, output := range c.config.Outputs {
+			if name == "" || name == output.Config.Name || name == output.Config.Alias {
+				plugins = append(plugins, output)
+				states = append(states, pluginState{
+					Type:  "outputs",
+					Name:  output.Config.Name,
+					Alias: output.Config.Alias,
+				})
+			}
+		}
+	}
+
+	return plugins, states
+}
+
+// serveList handles "GET /plugins".
+func (c *controlServer) serveList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	plugins, states := c.plugins("", "")
+	writeStates(w, plugins, states)
+}
+
+// serveAction handles "POST /plugins/<type>/<name>/<pause|resume>".
+func (c *controlServer) serveAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/plugins/"), "/")
+	if len(parts) != 3 || (parts[0] != "inputs" && parts[0] != "outputs") {
+		http.NotFound(w, r)
+		return
+	}
+	pluginType, name, action := parts[0], parts[1], parts[2]
+
+	plugins, states := c.plugins(pluginType, name)
+	if len(plugins) == 0 {
+		http.Error(w, fmt.Sprintf("no %s named %q", pluginType, name), http.StatusNotFound)
+		return
+	}
+
+	for _, plugin := range plugins {
+		switch action {
+		case "pause":
+			plugin.Pause()
+		case "resume":
+			plugin.Resume()
+		default:
+			http.NotFound(w, r)
+			return
+		}
+	}
+	log.Printf("I! [agent] Control API: %s %s.%s", action, pluginType, name)
+
+	writeStates(w, plugins, states)
+}
+
+func writeStates(w http.ResponseWriter, plugins []pauser, states []pluginState) {
+	for i, plugin := range plugins {
+		states[i].Paused = plugin.Paused()
+	}
+	if states == nil {
+		states = []pluginState{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(states)
+}
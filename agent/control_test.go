@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type controlInput struct{}
+
+func (i *controlInput) Description() string                   { return "" }
+func (i *controlInput) SampleConfig() string                  { return "" }
+func (i *controlInput) Gather(acc telegraf.Accumulator) error { return nil }
+
+type controlOutput struct{}
+
+func (o *controlOutput) Connect() error                        { return nil }
+func (o *controlOutput) Close() error                          { return nil }
+func (o *controlOutput) Description() string                   { return "" }
+func (o *controlOutput) SampleConfig() string                  { return "" }
+func (o *controlOutput) Write(metrics []telegraf.Metric) error { return nil }
+
+func newControlConfig() *config.Config {
+	return &config.Config{
+		Inputs: []*models.RunningInput{
+			models.NewRunningInput(&controlInput{}, &models.InputConfig{Name: "cpu"}),
+			models.NewRunningInput(&controlInput{}, &models.InputConfig{Name: "cpu", Alias: "cpu2"}),
+		},
+		Outputs: []*models.RunningOutput{
+			models.NewRunningOutput("file", &controlOutput{}, &models.OutputConfig{Name: "file"}, 0, 0),
+		},
+	}
+}
+
+const testToken = "secret"
+
+// controlRequest sends a request authenticated with the test token.
+func controlRequest(method, url string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	return http.DefaultClient.Do(req)
+}
+
+func TestControlPauseResume(t *testing.T) {
+	cfg := newControlConfig()
+	inputs, outputs := cfg.Inputs, cfg.Outputs
+	c, err := newControlServer("http://127.0.0.1:0", testToken, cfg)
+	require.NoError(t, err)
+	c.start()
+	defer c.stop()
+
+	url := "http://" + c.listener.Addr().String()
+
+	resp, err := controlRequest("POST", url+"/plugins/inputs/cpu2/pause")
+	require.NoError(t, err)
+	var states []pluginState
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&states))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []pluginState{{Type: "inputs", Name: "cpu", Alias: "cpu2", Paused: true}}, states)
+	assert.False(t, inputs[0].Paused())
+	assert.True(t, inputs[1].Paused())
+
+	resp, err = controlRequest("POST", url+"/plugins/outputs/file/pause")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.True(t, outputs[0].Paused())
+
+	resp, err = controlRequest("GET", url+"/plugins")
+	require.NoError(t, err)
+	states = nil
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&states))
+	resp.Body.Close()
+	assert.Equal(t, []pluginState{
+		{Type: "inputs", Name: "cpu", Paused: false},
+		{Type: "inputs", Name: "cpu", Alias: "cpu2", Paused: true},
+		{Type: "outputs", Name: "file", Paused: true},
+	}, states)
+
+	resp, err = controlRequest("POST", url+"/plugins/inputs/cpu/resume")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.False(t, inputs[0].Paused())
+	assert.False(t, inputs[1].Paused())
+
+	resp, err = controlRequest("POST", url+"/plugins/inputs/mem/pause")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = controlRequest("GET", url+"/plugins/inputs/cpu/pause")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestControlUnixSocket(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	socket := filepath.Join(tmpdir, "control.sock")
+	cfg := newControlConfig()
+	c, err := newControlServer("unix://"+socket, "", cfg)
+	require.NoError(t, err)
+	c.start()
+	defer c.stop()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", socket)
+			},
+		},
+	}
+	resp, err := client.Post("http://localhost/plugins/outputs/file/pause", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, cfg.Outputs[0].Paused())
+}
+
+func TestControlUnixSocketExistingFile(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	// A file which is not a socket is not removed.
+	path := filepath.Join(tmpdir, "telegraf.conf")
+	require.NoError(t, ioutil.WriteFile(path, []byte("[agent]\n"), 0600))
+	_, err = newControlServer("unix://"+path, "", newControlConfig())
+	require.Error(t, err)
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	// A socket left behind is replaced.
+	socket := filepath.Join(tmpdir, "control.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	c, err := newControlServer("unix://"+socket, "", newControlConfig())
+	require.NoError(t, err)
+	c.start()
+	c.stop()
+}
+
+func TestControlInvalidAddress(t *testing.T) {
+	_, err := newControlServer("udp://127.0.0.1:0", "", &config.Config{})
+	assert.Error(t, err)
+}
+
+func TestControlToken(t *testing.T) {
+	_, err := newControlServer("http://127.0.0.1:0", "", newControlConfig())
+	require.Error(t, err)
+
+	cfg := newControlConfig()
+	c, err := newControlServer("http://127.0.0.1:0", testToken, cfg)
+	require.NoError(t, err)
+	c.start()
+	defer c.stop()
+
+	url := "http://" + c.listener.Addr().String()
+	for _, auth := range []string{"", "Bearer wrong", testToken} {
+		req, err := http.NewRequest("POST", url+"/plugins/inputs/cpu/pause", nil)
+		require.NoError(t, err)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+	assert.False(t, cfg.Inputs[0].Paused())
+}
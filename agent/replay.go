@@ -0,0 +1,342 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	influxSerializer "github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// Replay data formats.
+const (
+	ReplayFormatInflux = "influx"
+	ReplayFormatJSON   = "json"
+)
+
+// Replay feeds the metrics recorded in r, in line protocol or in the JSON
+// data format, through the processors and aggregators.  Aggregation periods
+// follow the original timestamps of the metrics.
+//
+// Metrics are fed at speed times the rate they were recorded at, or as fast
+// as possible when speed is 0.  The resulting metrics are written to w in
+// line protocol, or to the configured outputs if w is nil.
+func (a *Agent) Replay(
+	ctx context.Context,
+	r io.Reader,
+	format string,
+	speed float64,
+	w io.Writer,
+) error {
+	log.Printf("D! [agent] Initializing plugins")
+	err := a.initPlugins()
+	if err != nil {
+		return err
+	}
+
+	if w == nil {
+		err = a.initCardinalityGuard()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("D! [agent] Connecting outputs")
+		err = a.connectOutputs(ctx)
+		if err != nil {
+			return err
+		}
+		defer a.closeOutputs()
+	}
+
+	inputC := make(chan telegraf.Metric, 100)
+	procC := make(chan telegraf.Metric, 100)
+	outputC := make(chan telegraf.Metric, 100)
+
+	log.Printf("D! [agent] Starting streaming processors")
+	stopProcessors, err := a.startStreamingProcessors(procC)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var replayErr error
+
+	src := inputC
+	dst := inputC
+
+	wg.Add(1)
+	go func(dst chan telegraf.Metric) {
+		defer wg.Done()
+
+		replayErr = a.replayMetrics(ctx, r, format, speed, dst)
+		close(dst)
+	}(dst)
+
+	src = dst
+
+	if len(a.Config.Processors) > 0 {
+		dst = procC
+
+		wg.Add(1)
+		go func(src, dst chan telegraf.Metric) {
+			defer wg.Done()
+
+			err := a.runProcessors(src, dst)
+			if err != nil {
+				log.Printf("E! [agent] Error running processors: %v", err)
+			}
+
+			log.Printf("D! [agent] Stopping streaming processors")
+			stopProcessors()
+			close(dst)
+		}(src, dst)
+
+		src = dst
+	}
+
+	if len(a.Config.Aggregators) > 0 {
+		dst = outputC
+
+		wg.Add(1)
+		go func(src, dst chan telegraf.Metric) {
+			defer wg.Done()
+
+			a.replayAggregators(src, dst)
+			close(dst)
+		}(src, dst)
+
+		src = dst
+	}
+
+	if w != nil {
+		s := influxSerializer.NewSerializer()
+		s.SetFieldSortOrder(influxSerializer.SortFields)
+		for metric := range src {
+			octets, err := s.Serialize(metric)
+			if err == nil {
+				w.Write(octets)
+			}
+			metric.Accept()
+		}
+	} else {
+		err := a.runOutputs(time.Now(), src)
+		if err != nil {
+			log.Printf("E! [agent] Error running outputs: %v", err)
+		}
+	}
+
+	wg.Wait()
+	return replayErr
+}
+
+// replayMetrics reads the metrics from r and sends them to dst, waiting
+// between metrics for the time elapsed between their timestamps divided by
+// speed.
+func (a *Agent) replayMetrics(
+	ctx context.Context,
+	r io.Reader,
+	format string,
+	speed float64,
+	dst chan<- telegraf.Metric,
+) error {
+	var last time.Time
+	return readMetrics(r, format, func(m telegraf.Metric) error {
+		if speed > 0 && !last.IsZero() && m.Time().After(last) {
+			wait := time.Duration(float64(m.Time().Sub(last)) / speed)
+			if err := internal.SleepContext(ctx, wait); err != nil {
+				return nil
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if m.Time().After(last) {
+			last = m.Time()
+		}
+
+		dst <- m
+		return nil
+	})
+}
+
+// replayAggregators adds the metrics to the aggregators, pushing them each
+// time a metric is past the end of their period.  The aggregation windows
+// start at the time of the first metric.
+func (a *Agent) replayAggregators(
+	src <-chan telegraf.Metric,
+	dst chan<- telegraf.Metric,
+) {
+	var started bool
+	for metric := range src {
+		if !started {
+			for _, agg := range a.Config.Aggregators {
+				since, until := updateWindow(metric.Time(), a.Config.Agent.RoundInterval, agg.Period())
+				agg.UpdateWindow(since, until)
+			}
+			started = true
+		}
+
+		for _, agg := range a.Config.Aggregators {
+			for !metric.Time().Before(agg.EndPeriod().Add(agg.Config.Delay)) {
+				a.replayPush(agg, dst)
+			}
+		}
+
+		var dropOriginal bool
+		for _, agg := range a.Config.Aggregators {
+			if ok := agg.Add(metric); ok {
+				dropOriginal = true
+			}
+		}
+
+		if !dropOriginal {
+			dst <- metric
+		} else {
+			metric.Drop()
+		}
+	}
+
+	if started {
+		for _, agg := range a.Config.Aggregators {
+			a.replayPush(agg, dst)
+		}
+	}
+}
+
+// replayPush pushes the aggregator, timestamping the aggregations at the end
+// of the period.
+func (a *Agent) replayPush(agg *models.RunningAggregator, dst chan<- telegraf.Metric) {
+	until := agg.EndPeriod()
+	for _, metric := range a.pushAggregations(agg, func() time.Time { return until }) {
+		dst <- metric
+	}
+}
+
+// readMetrics parses the metrics in r and calls fn for each of them.
+func readMetrics(r io.Reader, format string, fn func(telegraf.Metric) error) error {
+	switch format {
+	case ReplayFormatInflux, "":
+		return readLineProtocol(r, fn)
+	case ReplayFormatJSON:
+		return readJSON(r, fn)
+	default:
+		return fmt.Errorf("unsupported replay format %q", format)
+	}
+}
+
+func readLineProtocol(r io.Reader, fn func(telegraf.Metric) error) error {
+	parser := influx.NewParser(influx.NewMetricHandler())
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lineno int
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		m, err := parser.ParseLine(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", lineno, err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// jsonMetric is a metric or a batch of metrics in the JSON data format.
+type jsonMetric struct {
+	Name      string                 `json:"name"`
+	Tags      map[string]string      `json:"tags"`
+	Fields    map[string]interface{} `json:"fields"`
+	Timestamp json.Number            `json:"timestamp"`
+	Metrics   []jsonMetric           `json:"metrics"`
+}
+
+func readJSON(r io.Reader, fn func(telegraf.Metric) error) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for {
+		var jm jsonMetric
+		err := decoder.Decode(&jm)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		batch := jm.Metrics
+		if batch == nil {
+			batch = []jsonMetric{jm}
+		}
+		for _, jm := range batch {
+			m, err := jm.metric()
+			if err != nil {
+				return err
+			}
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (jm *jsonMetric) metric() (telegraf.Metric, error) {
+	fields := make(map[string]interface{}, len(jm.Fields))
+	for k, v := range jm.Fields {
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				v = i
+			} else if f, err := n.Float64(); err == nil {
+				v = f
+			}
+		}
+		fields[k] = v
+	}
+
+	t, err := jsonTime(jm.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("metric %s: %v", jm.Name, err)
+	}
+	return metric.New(jm.Name, jm.Tags, fields, t)
+}
+
+// jsonTime converts a JSON timestamp to a time, guessing the unit of integer
+// timestamps from their magnitude.
+func jsonTime(n json.Number) (time.Time, error) {
+	i, err := n.Int64()
+	if err != nil {
+		f, err := n.Float64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %q", n)
+		}
+		return time.Unix(0, int64(f*float64(time.Second))), nil
+	}
+
+	switch {
+	case i < 1e11:
+		return time.Unix(i, 0), nil
+	case i < 1e14:
+		return time.Unix(0, i*int64(time.Millisecond)), nil
+	case i < 1e17:
+		return time.Unix(0, i*int64(time.Microsecond)), nil
+	default:
+		return time.Unix(0, i), nil
+	}
+}
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayGolden(t *testing.T) {
+	c := config.NewConfig()
+	c.Aggregators = []*models.RunningAggregator{
+		models.NewRunningAggregator(&sumAggregator{}, &models.AggregatorConfig{
+			Name:   "sum",
+			Period: 10 * time.Second,
+			Filter: models.Filter{NamePass: []string{"cpu"}},
+		}),
+	}
+	require.NoError(t, c.Aggregators[0].Config.Filter.Compile())
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	f, err := os.Open("testdata/replay.lp")
+	require.NoError(t, err)
+	defer f.Close()
+
+	var buf bytes.Buffer
+	require.NoError(t, a.Replay(context.Background(), f, ReplayFormatInflux, 0, &buf))
+
+	golden, err := ioutil.ReadFile("testdata/replay.golden")
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), buf.String())
+}
+
+func TestReadMetricsJSON(t *testing.T) {
+	input := `{"fields":{"value":1.5},"name":"cpu","tags":{"host":"a"},"timestamp":1500000000}
+{"metrics":[{"fields":{"value":2,"state":"ok"},"name":"mem","tags":{},"timestamp":1500000000123}]}`
+
+	var metrics []telegraf.Metric
+	err := readMetrics(strings.NewReader(input), ReplayFormatJSON, func(m telegraf.Metric) error {
+		metrics = append(metrics, m)
+		return nil
+	})
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": 1.5},
+			time.Unix(1500000000, 0)),
+		testutil.MustMetric("mem",
+			map[string]string{},
+			map[string]interface{}{"value": int64(2), "state": "ok"},
+			time.Unix(0, 1500000000123*int64(time.Millisecond))),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestReadMetricsInvalid(t *testing.T) {
+	fn := func(m telegraf.Metric) error { return nil }
+
+	err := readMetrics(strings.NewReader("cpu value=1i 1\ncpu value=\n"), ReplayFormatInflux, fn)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+
+	err = readMetrics(strings.NewReader(""), "csv", fn)
+	assert.Error(t, err)
+}
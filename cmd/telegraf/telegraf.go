@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	_ "net/http/pprof" // Comment this line to disable pprof endpoint.
@@ -26,6 +27,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/outputs"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/kardianos/service"
 )
 
@@ -37,9 +39,15 @@ var fQuiet = flag.Bool("quiet", false,
 	"run in quiet mode")
 var fTest = flag.Bool("test", false, "enable test mode: gather metrics, print them out, and exit")
 var fTestWait = flag.Int("test-wait", 0, "wait up to this many seconds for service inputs to complete in test mode")
+var fTestPipeline = flag.Bool("test-pipeline", false, "test mode that also runs processors, aggregators and prints what each output would write")
+var fReplay = flag.String("replay", "", "replay the metrics recorded in this line protocol or JSON file through processors and aggregators, and exit")
+var fReplaySpeed = flag.Float64("replay-speed", 0, "replay at this many times the recorded rate, 0 replays as fast as possible")
+var fReplayStdout = flag.Bool("replay-stdout", false, "print the replayed metrics instead of writing them to the outputs")
+var configReloadFailed = selfstat.Register("agent", "config_reload_failed", map[string]string{})
+
 var fConfig = flag.String("config", "", "configuration file to load")
 var fConfigDirectory = flag.String("config-directory", "",
-	"directory containing additional *.conf files")
+	"directory containing additional *.conf, *.json and *.yaml files")
 var fVersion = flag.Bool("version", false, "display the version and exit")
 var fSampleConfig = flag.Bool("sample-config", false,
 	"print out full sample configuration")
@@ -94,16 +102,26 @@ func reloadLoop(
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == syscall.SIGHUP {
-					log.Printf("I! Reloading Telegraf config")
-					<-reload
-					reload <- true
+			for {
+				select {
+				case sig := <-signals:
+					if sig == syscall.SIGHUP {
+						log.Printf("I! Reloading Telegraf config")
+						_, err := loadConfig(inputFilters, outputFilters)
+						if err != nil {
+							log.Printf("E! [telegraf] Error reloading config, keeping the running config: %v", err)
+							configReloadFailed.Set(1)
+							continue
+						}
+						configReloadFailed.Set(0)
+						<-reload
+						reload <- true
+					}
+					cancel()
+				case <-stop:
+					cancel()
 				}
-				cancel()
-			case <-stop:
-				cancel()
+				return
 			}
 		}()
 
@@ -121,37 +139,11 @@ func runAgent(ctx context.Context,
 	log.Printf("I! Starting Telegraf %s", version)
 
 	// If no other options are specified, load the config file and run.
-	c := config.NewConfig()
-	c.OutputFilters = outputFilters
-	c.InputFilters = inputFilters
-	err := c.LoadConfig(*fConfig)
+	c, err := loadConfig(inputFilters, outputFilters)
 	if err != nil {
 		return err
 	}
 
-	if *fConfigDirectory != "" {
-		err = c.LoadDirectory(*fConfigDirectory)
-		if err != nil {
-			return err
-		}
-	}
-	if !*fTest && len(c.Outputs) == 0 {
-		return errors.New("Error: no outputs found, did you provide a valid config file?")
-	}
-	if *fPlugins == "" && len(c.Inputs) == 0 {
-		return errors.New("Error: no inputs found, did you provide a valid config file?")
-	}
-
-	if int64(c.Agent.Interval.Duration) <= 0 {
-		return fmt.Errorf("Agent interval must be positive, found %s",
-			c.Agent.Interval.Duration)
-	}
-
-	if int64(c.Agent.FlushInterval.Duration) <= 0 {
-		return fmt.Errorf("Agent flush_interval must be positive; found %s",
-			c.Agent.Interval.Duration)
-	}
-
 	ag, err := agent.NewAgent(c)
 	if err != nil {
 		return err
@@ -162,16 +154,26 @@ func runAgent(ctx context.Context,
 		Debug:               ag.Config.Agent.Debug || *fDebug,
 		Quiet:               ag.Config.Agent.Quiet || *fQuiet,
 		LogTarget:           ag.Config.Agent.LogTarget,
+		LogFormat:           ag.Config.Agent.LogFormat,
 		Logfile:             ag.Config.Agent.Logfile,
 		RotationInterval:    ag.Config.Agent.LogfileRotationInterval,
 		RotationMaxSize:     ag.Config.Agent.LogfileRotationMaxSize,
 		RotationMaxArchives: ag.Config.Agent.LogfileRotationMaxArchives,
+		SyslogAddress:       ag.Config.Agent.SyslogAddress,
+		SyslogFraming:       ag.Config.Agent.SyslogFraming,
 	}
 
 	logger.SetupLogging(logConfig)
 
+	if *fReplay != "" {
+		return replay(ctx, ag, *fReplay, *fReplaySpeed, *fReplayStdout)
+	}
+
 	if *fTest || *fTestWait != 0 {
 		testWaitDuration := time.Duration(*fTestWait) * time.Second
+		if *fTestPipeline {
+			return ag.TestPipeline(ctx, testWaitDuration)
+		}
 		return ag.Test(ctx, testWaitDuration)
 	}
 
@@ -202,6 +204,63 @@ func runAgent(ctx context.Context,
 	return ag.Run(ctx)
 }
 
+// loadConfig loads and validates the configuration files.
+func loadConfig(inputFilters []string, outputFilters []string) (*config.Config, error) {
+	c := config.NewConfig()
+	c.OutputFilters = outputFilters
+	c.InputFilters = inputFilters
+	err := c.LoadConfig(*fConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if *fConfigDirectory != "" {
+		err = c.LoadDirectory(*fConfigDirectory)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !*fTest && !*fReplayStdout && len(c.Outputs) == 0 {
+		return nil, errors.New("Error: no outputs found, did you provide a valid config file?")
+	}
+	if *fPlugins == "" && *fReplay == "" && len(c.Inputs) == 0 {
+		return nil, errors.New("Error: no inputs found, did you provide a valid config file?")
+	}
+
+	if int64(c.Agent.Interval.Duration) <= 0 {
+		return nil, fmt.Errorf("Agent interval must be positive, found %s",
+			c.Agent.Interval.Duration)
+	}
+
+	if int64(c.Agent.FlushInterval.Duration) <= 0 {
+		return nil, fmt.Errorf("Agent flush_interval must be positive; found %s",
+			c.Agent.Interval.Duration)
+	}
+
+	return c, nil
+}
+
+// replay feeds the metrics recorded in filename through the agent, detecting
+// the data format from the file extension.
+func replay(ctx context.Context, ag *agent.Agent, filename string, speed float64, stdout bool) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	format := agent.ReplayFormatInflux
+	if strings.HasSuffix(filename, ".json") {
+		format = agent.ReplayFormatJSON
+	}
+
+	var w io.Writer
+	if stdout {
+		w = os.Stdout
+	}
+	return ag.Replay(ctx, f, format, speed, w)
+}
+
 func usageExit(rc int) {
 	fmt.Println(internal.Usage)
 	os.Exit(rc)
@@ -261,6 +320,11 @@ func main() {
 	flag.Parse()
 	args := flag.Args()
 
+	// The pipeline dry run never writes to the outputs, it is a test mode.
+	if *fTestPipeline {
+		*fTest = true
+	}
+
 	sectionFilters, inputFilters, outputFilters := []string{}, []string{}, []string{}
 	if *fSectionFilters != "" {
 		sectionFilters = strings.Split(":"+strings.TrimSpace(*fSectionFilters)+":", ":")
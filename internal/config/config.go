@@ -21,12 +21,15 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/models"
+	framing "github.com/influxdata/telegraf/internal/syslog"
+	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/toml"
 	"github.com/influxdata/toml/ast"
 )
@@ -148,10 +151,15 @@ type AgentConfig struct {
 	Quiet bool `toml:"quiet"`
 
 	// Log target controls the destination for logs and can be one of "file",
-	// "stderr" or, on Windows, "eventlog".  When set to "file", the output file
-	// is determined by the "logfile" setting.
+	// "stderr", "syslog", "journald" or, on Windows, "eventlog".  When set to
+	// "file", the output file is determined by the "logfile" setting.
 	LogTarget string `toml:"logtarget"`
 
+	// Log format controls the format of the "file" and "stderr" logtargets and
+	// can be one of "text" or "json".  With "json" each line is a JSON object
+	// with the timestamp, level, plugin and message.
+	LogFormat string `toml:"logformat"`
+
 	// Name of the file to be logged to when using the "file" logtarget.  If set to
 	// the empty string then logs are written to stderr.
 	Logfile string `toml:"logfile"`
@@ -168,6 +176,46 @@ type AgentConfig struct {
 	// If set to -1, no archives are removed.
 	LogfileRotationMaxArchives int `toml:"logfile_rotation_max_archives"`
 
+	// Address of the syslog server when using the "syslog" logtarget, such as
+	// "udp://localhost:514" or "tcp://localhost:514".  If empty the messages
+	// are sent to the local syslog socket.
+	SyslogAddress string `toml:"syslog_address"`
+
+	// Framing of the messages sent to a syslog server over TCP, either
+	// "octet-counting" or "non-transparent".
+	SyslogFraming framing.Framing `toml:"syslog_framing"`
+
+	// Address of the control API used to inspect the agent and to pause and
+	// resume plugins, either a unix socket such as
+	// "unix:///var/run/telegraf/control.sock" or a TCP address such as
+	// "http://localhost:8126".  Disabled when empty.
+	ControlAddress string `toml:"control_address"`
+
+	// Bearer token required by the control API, mandatory on TCP addresses.
+	ControlToken string `toml:"control_token"`
+
+	// Ratio of the gathered metrics, between 0 and 1, traced through the
+	// processors, aggregators and outputs.  Each stage passed by a traced
+	// metric is logged with its trace ID.
+	TraceSampleRate float64 `toml:"trace_sample_rate"`
+
+	// Maximum number of series of each measurement sent to the outputs, 0
+	// for no limit.  The series are counted over all inputs, processors and
+	// aggregators, the estimates are reported by inputs.internal.
+	CardinalityLimit int `toml:"cardinality_limit"`
+
+	// Maximum number of measurements whose series are counted, the
+	// cardinality action is applied to the metrics of further measurements.
+	CardinalityMeasurementLimit int `toml:"cardinality_measurement_limit"`
+
+	// Action taken on the metrics of new series of a measurement over the
+	// cardinality limit, one of "drop", "strip_tags" or "alert".
+	CardinalityAction string `toml:"cardinality_action"`
+
+	// Interval at which the series counts are reset so that series no longer
+	// emitted are forgotten, 0 to never reset them.
+	CardinalityResetInterval internal.Duration `toml:"cardinality_reset_interval"`
+
 	Hostname     string
 	OmitHostname bool
 }
@@ -295,10 +343,15 @@ var agentConfig = `
   # quiet = false
 
   ## Log target controls the destination for logs and can be one of "file",
-  ## "stderr" or, on Windows, "eventlog".  When set to "file", the output file
-  ## is determined by the "logfile" setting.
+  ## "stderr", "syslog", "journald" or, on Windows, "eventlog".  When set to
+  ## "file", the output file is determined by the "logfile" setting.
   # logtarget = "file"
 
+  ## Log format controls the format of the "file" and "stderr" logtargets and
+  ## can be one of "text" or "json".  With "json" each line is a JSON object
+  ## with the timestamp, level, plugin type, name, alias and message.
+  # logformat = "text"
+
   ## Name of the file to be logged to when using the "file" logtarget.  If set to
   ## the empty string then logs are written to stderr.
   # logfile = ""
@@ -316,6 +369,43 @@ var agentConfig = `
   ## If set to -1, no archives are removed.
   # logfile_rotation_max_archives = 5
 
+  ## Address of the syslog server when using the "syslog" logtarget, messages
+  ## are sent using RFC5424.  If empty the local syslog socket is used.
+  # syslog_address = "udp://localhost:514"
+
+  ## Framing of the messages sent to a syslog server over TCP, either
+  ## "octet-counting" or "non-transparent".
+  # syslog_framing = "octet-counting"
+
+  ## Address of the control API used to inspect the agent and to pause and
+  ## resume inputs and outputs at runtime, either a unix socket or a TCP
+  ## address.
+  # control_address = "unix:///var/run/telegraf/control.sock"
+
+  ## Bearer token required in the Authorization header of the control API
+  ## requests, mandatory when the control API listens on a TCP address.
+  # control_token = "$TELEGRAF_CONTROL_TOKEN"
+
+  ## Ratio of the gathered metrics, between 0 and 1, to trace through the
+  ## processors, aggregators and outputs for debugging.  Each stage passed by
+  ## a traced metric is logged with its trace ID.
+  # trace_sample_rate = 0.0
+
+  ## Maximum number of series of each measurement sent to the outputs, 0 for
+  ## no limit.  The estimated number of series of each measurement is reported
+  ## by inputs.internal.
+  # cardinality_limit = 0
+  ## Maximum number of measurements whose series are counted, the action is
+  ## applied to all the metrics of further measurements.
+  # cardinality_measurement_limit = 1000
+  ## Action on the metrics of new series once a measurement is over the limit:
+  ##   drop       - drop the metrics
+  ##   strip_tags - remove the tag with the most values from the metrics
+  ##   alert      - log a warning and keep the metrics
+  # cardinality_action = "alert"
+  ## Interval at which the series counts are reset, 0 to never reset them.
+  # cardinality_reset_interval = "0s"
+
   ## Override default hostname, if empty use os.Hostname()
   hostname = ""
   ## If set to true, do no set the "host" tag in the telegraf agent.
@@ -633,8 +723,7 @@ func (c *Config) LoadDirectory(path string) error {
 
 			return nil
 		}
-		name := info.Name()
-		if len(name) < 6 || name[len(name)-5:] != ".conf" {
+		if !isConfigFile(info.Name()) {
 			return nil
 		}
 		err := c.LoadConfig(thispath)
@@ -687,7 +776,7 @@ func (c *Config) LoadConfig(path string) error {
 		return fmt.Errorf("Error loading %s, %s", path, err)
 	}
 
-	tbl, err := parseConfig(data)
+	tbl, err := parseConfigFormat(data, configFormat(path))
 	if err != nil {
 		return fmt.Errorf("Error parsing %s, %s", path, err)
 	}
@@ -877,8 +966,13 @@ func fetchConfig(u *url.URL) ([]byte, error) {
 // returns the AST produced from the TOML parser. When loading the file, it
 // will find environment variables and replace them.
 func parseConfig(contents []byte) (*ast.Table, error) {
-	contents = trimBOM(contents)
+	contents = replaceEnvVars(trimBOM(contents))
+	return toml.Parse(contents)
+}
 
+// replaceEnvVars replaces environment variables in the contents with their
+// escaped values, unset variables are left as is.
+func replaceEnvVars(contents []byte) []byte {
 	parameters := envVarRe.FindAllSubmatch(contents, -1)
 	for _, parameter := range parameters {
 		if len(parameter) != 3 {
@@ -901,10 +995,13 @@ func parseConfig(contents []byte) (*ast.Table, error) {
 		}
 	}
 
-	return toml.Parse(contents)
+	return contents
 }
 
 func (c *Config) addAggregator(name string, table *ast.Table) error {
+	if enabled, err := isEnabled(table); err != nil || !enabled {
+		return err
+	}
 	creator, ok := aggregators.Aggregators[name]
 	if !ok {
 		return fmt.Errorf("Undefined but requested aggregator: %s", name)
@@ -915,6 +1012,7 @@ func (c *Config) addAggregator(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	c.setLogRotation(&conf.Log)
 
 	if err := toml.UnmarshalTable(table, aggregator); err != nil {
 		return err
@@ -925,6 +1023,9 @@ func (c *Config) addAggregator(name string, table *ast.Table) error {
 }
 
 func (c *Config) addProcessor(name string, table *ast.Table) error {
+	if enabled, err := isEnabled(table); err != nil || !enabled {
+		return err
+	}
 	creator, ok := processors.Processors[name]
 	if !ok {
 		return fmt.Errorf("Undefined but requested processor: %s", name)
@@ -935,6 +1036,36 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	c.setLogRotation(&processorConfig.Log)
+
+	// Processors exchanging metrics with an external program use the same
+	// data_format to serialize and parse them.
+	if t, ok := processor.(serializers.SerializerOutput); ok {
+		// Build the serializer from a copy of the table, keeping the
+		// options shared with the parser.
+		stbl := &ast.Table{Fields: make(map[string]interface{}, len(table.Fields))}
+		for k, v := range table.Fields {
+			stbl.Fields[k] = v
+		}
+		serializer, err := buildSerializer(name, stbl)
+		if err != nil {
+			return err
+		}
+		t.SetSerializer(serializer)
+
+		for k := range table.Fields {
+			if _, ok := stbl.Fields[k]; !ok && k != "data_format" {
+				delete(table.Fields, k)
+			}
+		}
+	}
+	if t, ok := processor.(parsers.ParserInput); ok {
+		parser, err := buildParser(name, table)
+		if err != nil {
+			return err
+		}
+		t.SetParser(parser)
+	}
 
 	if err := toml.UnmarshalTable(table, processor); err != nil {
 		return err
@@ -950,6 +1081,9 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	if len(c.OutputFilters) > 0 && !sliceContains(name, c.OutputFilters) {
 		return nil
 	}
+	if enabled, err := isEnabled(table); err != nil || !enabled {
+		return err
+	}
 	creator, ok := outputs.Outputs[name]
 	if !ok {
 		return fmt.Errorf("Undefined but requested output: %s", name)
@@ -958,9 +1092,11 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
+	var serializer serializers.Serializer
 	switch t := output.(type) {
 	case serializers.SerializerOutput:
-		serializer, err := buildSerializer(name, table)
+		var err error
+		serializer, err = buildSerializer(name, table)
 		if err != nil {
 			return err
 		}
@@ -971,6 +1107,7 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	c.setLogRotation(&outputConfig.Log)
 
 	if err := toml.UnmarshalTable(table, output); err != nil {
 		return err
@@ -978,6 +1115,15 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 
 	ro := models.NewRunningOutput(name, output, outputConfig,
 		c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+	if serializer != nil {
+		ro.Serializer = serializer
+	} else {
+		// Outputs without a data format are shown in line protocol in test
+		// mode.
+		s := influx.NewSerializer()
+		s.SetFieldSortOrder(influx.SortFields)
+		ro.Serializer = s
+	}
 	c.Outputs = append(c.Outputs, ro)
 	return nil
 }
@@ -986,6 +1132,9 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 	if len(c.InputFilters) > 0 && !sliceContains(name, c.InputFilters) {
 		return nil
 	}
+	if enabled, err := isEnabled(table); err != nil || !enabled {
+		return err
+	}
 	// Legacy support renaming io input to diskio
 	if name == "io" {
 		name = "diskio"
@@ -1023,6 +1172,7 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	c.setLogRotation(&pluginConfig.Log)
 
 	if err := toml.UnmarshalTable(table, input); err != nil {
 		return err
@@ -1146,6 +1296,10 @@ func buildAggregator(name string, tbl *ast.Table) (*models.AggregatorConfig, err
 	delete(tbl.Fields, "alias")
 	delete(tbl.Fields, "tags")
 	var err error
+	conf.Log, err = buildLogConfig(tbl)
+	if err != nil {
+		return conf, err
+	}
 	conf.Filter, err = buildFilter(tbl)
 	if err != nil {
 		return conf, err
@@ -1182,6 +1336,10 @@ func buildProcessor(name string, tbl *ast.Table) (*models.ProcessorConfig, error
 	delete(tbl.Fields, "alias")
 	delete(tbl.Fields, "order")
 	var err error
+	conf.Log, err = buildLogConfig(tbl)
+	if err != nil {
+		return conf, err
+	}
 	conf.Filter, err = buildFilter(tbl)
 	if err != nil {
 		return conf, err
@@ -1326,6 +1484,81 @@ func buildFilter(tbl *ast.Table) (models.Filter, error) {
 	return f, nil
 }
 
+// buildLogConfig parses the logging options common to all plugins
+// (log_level/log_rate_limit/logfile) to be inserted into the plugin config.
+func buildLogConfig(tbl *ast.Table) (models.LogConfig, error) {
+	lc := models.LogConfig{}
+
+	if node, ok := tbl.Fields["log_level"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				if _, err := logger.ParseLevel(str.Value); err != nil {
+					return lc, err
+				}
+				lc.Level = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["log_rate_limit"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return lc, err
+				}
+
+				lc.RateLimit = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["logfile"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				lc.Logfile = str.Value
+			}
+		}
+	}
+
+	delete(tbl.Fields, "log_level")
+	delete(tbl.Fields, "log_rate_limit")
+	delete(tbl.Fields, "logfile")
+	return lc, nil
+}
+
+// isEnabled parses and removes the enabled option common to all plugins,
+// plugins with enabled set to false are not loaded.
+func isEnabled(tbl *ast.Table) (bool, error) {
+	enabled := true
+	if node, ok := tbl.Fields["enabled"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				enabled, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return false, err
+				}
+			}
+		}
+	}
+
+	delete(tbl.Fields, "enabled")
+	return enabled, nil
+}
+
+// setLogRotation applies the agent log format and logfile rotation settings
+// to the plugin logfile.
+func (c *Config) setLogRotation(lc *models.LogConfig) {
+	if lc.Logfile == "" {
+		return
+	}
+	lc.LogFormat = c.Agent.LogFormat
+	lc.LogfileRotationInterval = c.Agent.LogfileRotationInterval.Duration
+	lc.LogfileRotationMaxSize = c.Agent.LogfileRotationMaxSize.Size
+	lc.LogfileRotationMaxArchives = c.Agent.LogfileRotationMaxArchives
+}
+
 // buildInput parses input specific items from the ast.Table,
 // builds the filter and returns a
 // models.InputConfig to be inserted into models.RunningInput
@@ -1376,6 +1609,40 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["max_metrics_per_interval"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				cp.MaxMetricsPerInterval = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["sample_ratio"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			switch v := kv.Value.(type) {
+			case *ast.Float:
+				ratio, err := v.Float()
+				if err != nil {
+					return nil, err
+				}
+				cp.SampleRatio = ratio
+			case *ast.Integer:
+				ratio, err := v.Int()
+				if err != nil {
+					return nil, err
+				}
+				cp.SampleRatio = float64(ratio)
+			}
+			if cp.SampleRatio < 0 || cp.SampleRatio > 1 {
+				return nil, fmt.Errorf("sample_ratio must be between 0 and 1, got %v", cp.SampleRatio)
+			}
+		}
+	}
+
 	cp.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
 		if subtbl, ok := node.(*ast.Table); ok {
@@ -1390,8 +1657,14 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 	delete(tbl.Fields, "name_override")
 	delete(tbl.Fields, "alias")
 	delete(tbl.Fields, "interval")
+	delete(tbl.Fields, "max_metrics_per_interval")
+	delete(tbl.Fields, "sample_ratio")
 	delete(tbl.Fields, "tags")
 	var err error
+	cp.Log, err = buildLogConfig(tbl)
+	if err != nil {
+		return cp, err
+	}
 	cp.Filter, err = buildFilter(tbl)
 	if err != nil {
 		return cp, err
@@ -2012,9 +2285,14 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	logConfig, err := buildLogConfig(tbl)
+	if err != nil {
+		return nil, err
+	}
 	oc := &models.OutputConfig{
 		Name:   name,
 		Filter: filter,
+		Log:    logConfig,
 	}
 
 	// TODO
@@ -2084,11 +2362,20 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["idempotency_tag"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				oc.IdempotencyTag = str.Value
+			}
+		}
+	}
+
 	delete(tbl.Fields, "flush_interval")
 	delete(tbl.Fields, "flush_jitter")
 	delete(tbl.Fields, "metric_buffer_limit")
 	delete(tbl.Fields, "metric_batch_size")
 	delete(tbl.Fields, "alias")
+	delete(tbl.Fields, "idempotency_tag")
 
 	return oc, nil
 }
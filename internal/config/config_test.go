@@ -1,6 +1,8 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"testing"
 	"time"
@@ -256,3 +258,88 @@ func TestConfig_BadOrdering(t *testing.T) {
 	require.Error(t, err, "bad ordering")
 	assert.Equal(t, "Error parsing ./testdata/non_slice_slice.toml, line 4: cannot unmarshal TOML array into string (need slice)", err.Error())
 }
+
+func TestConfig_Disabled(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfig("./testdata/disabled.toml")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(c.Inputs))
+	require.Equal(t, 1, len(c.Outputs))
+	assert.Equal(t, "enabled", c.Inputs[0].Config.Alias)
+	assert.Equal(t, "enabled", c.Outputs[0].Config.Alias)
+}
+
+func TestConfig_LoadSingleInputStructured(t *testing.T) {
+	for _, path := range []string{"./testdata/single_plugin.json", "./testdata/single_plugin.yaml"} {
+		t.Run(path, func(t *testing.T) {
+			expected := NewConfig()
+			require.NoError(t, expected.LoadConfig("./testdata/single_plugin.toml"))
+
+			c := NewConfig()
+			require.NoError(t, c.LoadConfig(path))
+			require.Equal(t, 1, len(c.Inputs))
+
+			assert.Equal(t, expected.Inputs[0].Input, c.Inputs[0].Input,
+				"Testdata did not produce a correct memcached struct.")
+			assert.Equal(t, expected.Inputs[0].Config, c.Inputs[0].Config,
+				"Testdata did not produce correct memcached metadata.")
+		})
+	}
+}
+
+func TestConfig_LoadDirectoryMixedFormats(t *testing.T) {
+	expected := NewConfig()
+	require.NoError(t, expected.LoadDirectory("./testdata/subconfig"))
+
+	c := NewConfig()
+	require.NoError(t, c.LoadDirectory("./testdata/subconfig_mixed"))
+	require.Equal(t, len(expected.Inputs), len(c.Inputs))
+
+	for i := range expected.Inputs {
+		assert.Equal(t, expected.Inputs[i].Config, c.Inputs[i].Config)
+	}
+	assert.Equal(t, expected.Inputs[2].Input, c.Inputs[2].Input)
+}
+
+func TestConfigFormat(t *testing.T) {
+	assert.Equal(t, formatTOML, configFormat("/etc/telegraf/telegraf.conf"))
+	assert.Equal(t, formatJSON, configFormat("/etc/telegraf/telegraf.json"))
+	assert.Equal(t, formatYAML, configFormat("/etc/telegraf/telegraf.yaml"))
+	assert.Equal(t, formatYAML, configFormat("/etc/telegraf/telegraf.YML"))
+	assert.Equal(t, formatJSON, configFormat("http://localhost/api/config.json?id=1"))
+	assert.Equal(t, formatTOML, configFormat("http://localhost/api/config"))
+}
+
+func TestEncodeTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeTable(&buf, nil, map[string]interface{}{
+		"agent": map[string]interface{}{
+			"interval": "10s",
+			"debug":    true,
+		},
+		"outputs": map[string]interface{}{
+			"http": []interface{}{
+				map[string]interface{}{
+					"url":     "http://localhost",
+					"timeout": json.Number("5"),
+					"headers": map[string]interface{}{
+						"Content-Type": "text/plain; \"quoted\"",
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	expected := `[agent]
+debug = true
+interval = "10s"
+[outputs]
+[[outputs.http]]
+timeout = 5
+url = "http://localhost"
+[outputs.http.headers]
+Content-Type = "text/plain; \"quoted\""
+`
+	assert.Equal(t, expected, buf.String())
+}
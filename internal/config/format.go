@@ -0,0 +1,260 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/influxdata/toml"
+	"github.com/influxdata/toml/ast"
+)
+
+const (
+	formatTOML = "toml"
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+var (
+	// pluginSections are the sections where each plugin is configured as a
+	// list of tables.
+	pluginSections = []string{"inputs", "outputs", "processors", "aggregators", "plugins"}
+
+	bareKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// configFormat returns the format of the configuration file based on the
+// extension of the path, falling back to TOML.
+func configFormat(path string) string {
+	if u, err := url.Parse(path); err == nil && u.Scheme != "" && u.Path != "" {
+		path = u.Path
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return formatJSON
+	case ".yaml", ".yml":
+		return formatYAML
+	default:
+		return formatTOML
+	}
+}
+
+// isConfigFile returns true if the file in a configuration directory should
+// be loaded.
+func isConfigFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".conf", ".json", ".yaml", ".yml":
+		return len(name) > len(filepath.Ext(name))
+	default:
+		return false
+	}
+}
+
+// parseConfigFormat parses a configuration in the given format and returns
+// the same AST that the TOML parser produces, so that the plugin builders can
+// be used regardless of the format of the file.
+func parseConfigFormat(contents []byte, format string) (*ast.Table, error) {
+	switch format {
+	case formatJSON, formatYAML:
+		return parseStructuredConfig(contents, format)
+	default:
+		return parseConfig(contents)
+	}
+}
+
+// parseStructuredConfig loads a JSON or YAML configuration, replaces
+// environment variables and converts it to the TOML AST.
+func parseStructuredConfig(contents []byte, format string) (*ast.Table, error) {
+	contents = replaceEnvVars(trimBOM(contents))
+
+	if format == formatYAML {
+		var err error
+		contents, err = yaml.YAMLToJSON(contents)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var root map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(contents))
+	dec.UseNumber()
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	// Plugins may be given as a single object instead of a list of objects,
+	// the TOML equivalent of [inputs.cpu] instead of [[inputs.cpu]].
+	for _, section := range pluginSections {
+		plugins, ok := root[section].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for name, plugin := range plugins {
+			if table, ok := plugin.(map[string]interface{}); ok {
+				plugins[name] = []interface{}{table}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := encodeTable(&buf, nil, root); err != nil {
+		return nil, err
+	}
+	return toml.Parse(buf.Bytes())
+}
+
+// encodeTable writes the table as TOML, keys with plain values are written
+// first followed by the sub-tables.
+func encodeTable(buf *bytes.Buffer, path []string, table map[string]interface{}) error {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := table[k]
+		if v == nil || isTable(v) || isTableArray(v) {
+			continue
+		}
+		value, err := encodeValue(v)
+		if err != nil {
+			return fmt.Errorf("%s: %s", strings.Join(append(path, k), "."), err)
+		}
+		fmt.Fprintf(buf, "%s = %s\n", encodeKey(k), value)
+	}
+
+	for _, k := range keys {
+		subpath := append(path[:len(path):len(path)], encodeKey(k))
+		switch v := table[k].(type) {
+		case map[string]interface{}:
+			fmt.Fprintf(buf, "[%s]\n", strings.Join(subpath, "."))
+			if err := encodeTable(buf, subpath, v); err != nil {
+				return err
+			}
+		case []interface{}:
+			if !isTableArray(v) {
+				continue
+			}
+			for _, elem := range v {
+				fmt.Fprintf(buf, "[[%s]]\n", strings.Join(subpath, "."))
+				if err := encodeTable(buf, subpath, elem.(map[string]interface{})); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// encodeValue returns the inline TOML representation of a value.
+func encodeValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return encodeString(v), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case json.Number:
+		return v.String(), nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			value, err := encodeValue(elem)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, value)
+		}
+		return "[" + strings.Join(values, ", ") + "]", nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		values := make([]string, 0, len(v))
+		for _, k := range keys {
+			if v[k] == nil {
+				continue
+			}
+			value, err := encodeValue(v[k])
+			if err != nil {
+				return "", err
+			}
+			values = append(values, encodeKey(k)+" = "+value)
+		}
+		return "{" + strings.Join(values, ", ") + "}", nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// encodeKey quotes the key if it cannot be used as a bare TOML key.
+func encodeKey(key string) string {
+	if bareKeyRe.MatchString(key) {
+		return key
+	}
+	return encodeString(key)
+}
+
+// encodeString returns the string as a TOML basic string.
+func encodeString(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\u%04X`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+func isTable(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+	return ok
+}
+
+// isTableArray returns true if the value is a non-empty list of objects.
+func isTableArray(v interface{}) bool {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return false
+	}
+	for _, elem := range list {
+		if !isTable(elem) {
+			return false
+		}
+	}
+	return true
+}
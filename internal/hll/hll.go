@@ -0,0 +1,77 @@
+// Package hll estimates the number of distinct items in a stream using the
+// HyperLogLog algorithm, in a fixed amount of memory.
+package hll
+
+import (
+	"math"
+	"math/bits"
+)
+
+const (
+	// precision is the number of bits of the hash selecting the register,
+	// giving a standard error of about 1.6%.
+	precision = 12
+	registers = 1 << precision
+)
+
+// Sketch estimates the number of distinct hashes inserted in it.
+type Sketch struct {
+	regs [registers]uint8
+}
+
+// New returns an empty Sketch.
+func New() *Sketch {
+	return &Sketch{}
+}
+
+// Insert adds the hash of an item to the sketch.  It returns true if the
+// estimate may have changed; inserting an item already in the sketch never
+// changes it.
+func (s *Sketch) Insert(hash uint64) bool {
+	// Hashes such as FNV are not uniform enough in their top bits.
+	hash = mix(hash)
+
+	index := hash >> (64 - precision)
+	rank := uint8(bits.LeadingZeros64(hash<<precision|1<<(precision-1))) + 1
+	if rank > s.regs[index] {
+		s.regs[index] = rank
+		return true
+	}
+	return false
+}
+
+// Estimate returns the estimated number of distinct hashes inserted.
+func (s *Sketch) Estimate() uint64 {
+	var sum float64
+	var zeros int
+	for _, r := range s.regs {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	m := float64(registers)
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+
+	// Linear counting is more accurate for small cardinalities.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Reset empties the sketch.
+func (s *Sketch) Reset() {
+	s.regs = [registers]uint8{}
+}
+
+// mix is the finalizer of splitmix64.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,46 @@
+package hll
+
+import (
+	"hash/fnv"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []uint64{0, 1, 10, 100, 1000, 10000, 100000}
+	for _, n := range tests {
+		t.Run(strconv.FormatUint(n, 10), func(t *testing.T) {
+			s := New()
+			for i := uint64(0); i < n; i++ {
+				s.Insert(hash("series" + strconv.FormatUint(i, 10)))
+			}
+			require.InEpsilon(t, float64(n)+1, float64(s.Estimate())+1, 0.05)
+		})
+	}
+}
+
+func TestInsertDuplicate(t *testing.T) {
+	s := New()
+	require.True(t, s.Insert(hash("a")))
+	require.False(t, s.Insert(hash("a")))
+
+	for i := 0; i < 1000; i++ {
+		s.Insert(hash("a"))
+	}
+	require.Equal(t, uint64(1), s.Estimate())
+}
+
+func TestReset(t *testing.T) {
+	s := New()
+	s.Insert(hash("a"))
+	s.Reset()
+	require.Equal(t, uint64(0), s.Estimate())
+}
@@ -24,6 +24,8 @@ type Buffer struct {
 	batchFirst int // index of the first metric in the batch
 	batchSize  int // number of metrics currently in the batch
 
+	name string // log name of the output, used for tracing
+
 	MetricsAdded   selfstat.Stat
 	MetricsWritten selfstat.Stat
 	MetricsDropped selfstat.Stat
@@ -39,6 +41,7 @@ func NewBuffer(name string, alias string, capacity int) *Buffer {
 		last:  0,
 		size:  0,
 		cap:   capacity,
+		name:  logName("outputs", name, alias),
 
 		MetricsAdded: selfstat.Register(
 			"write",
@@ -83,19 +86,22 @@ func (b *Buffer) length() int {
 	return min(b.size+b.batchSize, b.cap)
 }
 
-func (b *Buffer) metricAdded() {
+func (b *Buffer) metricAdded(metric telegraf.Metric) {
 	b.MetricsAdded.Incr(1)
+	Trace(metric, b.name, "added to buffer")
 }
 
 func (b *Buffer) metricWritten(metric telegraf.Metric) {
 	AgentMetricsWritten.Incr(1)
 	b.MetricsWritten.Incr(1)
+	Trace(metric, b.name, "written")
 	metric.Accept()
 }
 
 func (b *Buffer) metricDropped(metric telegraf.Metric) {
 	AgentMetricsDropped.Incr(1)
 	b.MetricsDropped.Incr(1)
+	Trace(metric, b.name, "dropped, buffer is full")
 	metric.Reject()
 }
 
@@ -112,7 +118,7 @@ func (b *Buffer) add(m telegraf.Metric) int {
 		}
 	}
 
-	b.metricAdded()
+	b.metricAdded(m)
 
 	b.buf[b.last] = m
 	b.last = b.next(b.last)
@@ -175,10 +181,21 @@ func (b *Buffer) Accept(batch []telegraf.Metric) {
 	b.Lock()
 	defer b.Unlock()
 
-	for _, m := range batch {
-		b.metricWritten(m)
-	}
+	b.accept(batch)
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
 
+// AcceptPartial marks the first written metrics of the batch, acquired from
+// Batch(), as successfully written and returns the remaining metrics to the
+// buffer as with Reject.
+func (b *Buffer) AcceptPartial(batch []telegraf.Metric, written int) {
+	b.Lock()
+	defer b.Unlock()
+
+	written = max(0, min(written, len(batch)))
+	b.accept(batch[:written])
+	b.reject(batch[written:])
 	b.resetBatch()
 	b.BufferSize.Set(int64(b.length()))
 }
@@ -189,6 +206,20 @@ func (b *Buffer) Reject(batch []telegraf.Metric) {
 	b.Lock()
 	defer b.Unlock()
 
+	b.reject(batch)
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+func (b *Buffer) accept(batch []telegraf.Metric) {
+	for _, m := range batch {
+		b.metricWritten(m)
+	}
+}
+
+// reject restores the metrics of the batch in the space left by Batch(), the
+// batch may be any tail of the batch returned by Batch().
+func (b *Buffer) reject(batch []telegraf.Metric) {
 	if len(batch) == 0 {
 		return
 	}
@@ -227,9 +258,6 @@ func (b *Buffer) Reject(batch []telegraf.Metric) {
 			b.metricDropped(batch[i])
 		}
 	}
-
-	b.resetBatch()
-	b.BufferSize.Set(int64(b.length()))
 }
 
 // dist returns the distance between two indexes.  Because this data structure
@@ -279,3 +307,10 @@ func min(a, b int) int {
 	}
 	return a
 }
+
+func max(a, b int) int {
+	if b > a {
+		return b
+	}
+	return a
+}
@@ -726,3 +726,46 @@ func TestBuffer_RejectEmptyBatch(t *testing.T) {
 		require.NotNil(t, m)
 	}
 }
+
+func TestBuffer_AcceptPartial(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(1))
+	b.Add(MetricTime(2))
+	b.Add(MetricTime(3))
+	b.Add(MetricTime(4))
+	batch := b.Batch(3)
+
+	b.Add(MetricTime(5))
+	b.AcceptPartial(batch, 1)
+
+	require.Equal(t, int64(1), b.MetricsWritten.Get())
+	require.Equal(t, int64(0), b.MetricsDropped.Get())
+	require.Equal(t, 4, b.Len())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			MetricTime(5),
+			MetricTime(3),
+			MetricTime(2),
+			MetricTime(1),
+		}, batch)
+}
+
+func TestBuffer_AcceptPartialOutOfRange(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(1))
+	b.Add(MetricTime(2))
+	batch := b.Batch(2)
+	b.AcceptPartial(batch, 3)
+
+	require.Equal(t, int64(2), b.MetricsWritten.Get())
+	require.Equal(t, 0, b.Len())
+
+	b.Add(MetricTime(3))
+	batch = b.Batch(2)
+	b.AcceptPartial(batch, -1)
+
+	require.Equal(t, int64(2), b.MetricsWritten.Get())
+	require.Equal(t, 1, b.Len())
+}
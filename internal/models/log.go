@@ -1,59 +1,265 @@
 package models
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"reflect"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/wlog"
 )
 
 // Logger defines a logging structure for plugins.
 type Logger struct {
 	Errs selfstat.Stat
 	Name string // Name is the plugin name, will be printed in the `[]`.
+
+	// Level is the minimum level logged for the plugin, when unset the agent
+	// log level applies.
+	Level wlog.Level
+	// RateLimit is the minimum interval between logging identical errors.
+	RateLimit time.Duration
+	// Output is the destination of the log messages, when unset the messages
+	// are written to the agent log.
+	Output io.Writer
+
+	mu            sync.Mutex
+	errors        map[string]*repeatedError
+	lastError     string
+	lastErrorTime time.Time
+	// flushTimer reports the suppressed errors once their rate limit
+	// expires, when they are not logged again.
+	flushTimer *time.Timer
+}
+
+// repeatedError tracks an error message suppressed by the rate limit.
+type repeatedError struct {
+	logged     time.Time
+	suppressed int
+}
+
+// LogConfig is the logging configuration common to all plugins.
+type LogConfig struct {
+	Level     string
+	RateLimit time.Duration
+
+	// Logfile routes the plugin logs to their own file, written in the agent
+	// log format and rotated with the agent logfile rotation settings.
+	Logfile                    string
+	LogFormat                  string
+	LogfileRotationInterval    time.Duration
+	LogfileRotationMaxSize     int64
+	LogfileRotationMaxArchives int
 }
 
 // Errorf logs an error message, patterned after log.Printf.
 func (l *Logger) Errorf(format string, args ...interface{}) {
 	l.Errs.Incr(1)
-	log.Printf("E! ["+l.Name+"] "+format, args...)
+	l.printError(fmt.Sprintf(format, args...))
+}
+
+// LastError returns the last error logged by the plugin and when it was
+// logged, including errors suppressed by the rate limit.
+func (l *Logger) LastError() (time.Time, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastErrorTime, l.lastError
 }
 
 // Error logs an error message, patterned after log.Print.
 func (l *Logger) Error(args ...interface{}) {
 	l.Errs.Incr(1)
-	log.Print(append([]interface{}{"E! [" + l.Name + "] "}, args...)...)
+	l.printError(fmt.Sprint(args...))
 }
 
 // Debugf logs a debug message, patterned after log.Printf.
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	log.Printf("D! ["+l.Name+"] "+format, args...)
+	l.print(wlog.DEBUG, fmt.Sprintf(format, args...))
 }
 
 // Debug logs a debug message, patterned after log.Print.
 func (l *Logger) Debug(args ...interface{}) {
-	log.Print(append([]interface{}{"D! [" + l.Name + "] "}, args...)...)
+	l.print(wlog.DEBUG, fmt.Sprint(args...))
 }
 
 // Warnf logs a warning message, patterned after log.Printf.
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	log.Printf("W! ["+l.Name+"] "+format, args...)
+	l.print(wlog.WARN, fmt.Sprintf(format, args...))
 }
 
 // Warn logs a warning message, patterned after log.Print.
 func (l *Logger) Warn(args ...interface{}) {
-	log.Print(append([]interface{}{"W! [" + l.Name + "] "}, args...)...)
+	l.print(wlog.WARN, fmt.Sprint(args...))
 }
 
 // Infof logs an information message, patterned after log.Printf.
 func (l *Logger) Infof(format string, args ...interface{}) {
-	log.Printf("I! ["+l.Name+"] "+format, args...)
+	l.print(wlog.INFO, fmt.Sprintf(format, args...))
 }
 
 // Info logs an information message, patterned after log.Print.
 func (l *Logger) Info(args ...interface{}) {
-	log.Print(append([]interface{}{"I! [" + l.Name + "] "}, args...)...)
+	l.print(wlog.INFO, fmt.Sprint(args...))
+}
+
+// printError logs an error message unless an identical message was logged
+// within the rate limit.
+func (l *Logger) printError(msg string) {
+	now := time.Now()
+
+	l.mu.Lock()
+	l.lastError = msg
+	l.lastErrorTime = now
+
+	if l.RateLimit <= 0 {
+		l.mu.Unlock()
+		l.print(wlog.ERROR, msg)
+		return
+	}
+
+	if l.errors == nil {
+		l.errors = make(map[string]*repeatedError)
+	}
+
+	if e, ok := l.errors[msg]; ok && now.Sub(e.logged) < l.RateLimit {
+		e.suppressed++
+		if l.flushTimer == nil {
+			l.flushTimer = time.AfterFunc(e.logged.Add(l.RateLimit).Sub(now), l.flushErrors)
+		}
+		l.mu.Unlock()
+		return
+	}
+
+	var suppressed int
+	if e, ok := l.errors[msg]; ok {
+		suppressed = e.suppressed
+	}
+
+	// Forget errors that are no longer repeating, reporting how many times
+	// they were suppressed since they were logged.
+	var pruned []string
+	for k, e := range l.errors {
+		if k == msg || now.Sub(e.logged) < l.RateLimit {
+			continue
+		}
+		if e.suppressed > 0 {
+			pruned = append(pruned, repeated(k, e.suppressed))
+		}
+		delete(l.errors, k)
+	}
+	l.errors[msg] = &repeatedError{logged: now}
+	l.mu.Unlock()
+
+	sort.Strings(pruned)
+	for _, p := range pruned {
+		l.print(wlog.ERROR, p)
+	}
+	l.print(wlog.ERROR, repeated(msg, suppressed))
+}
+
+// flushErrors logs the number of times the errors whose rate limit expired
+// were suppressed, and schedules the next flush if errors are still
+// suppressed.
+func (l *Logger) flushErrors() {
+	now := time.Now()
+
+	l.mu.Lock()
+	l.flushTimer = nil
+	var flushed []string
+	var next time.Time
+	for k, e := range l.errors {
+		expires := e.logged.Add(l.RateLimit)
+		if now.Before(expires) {
+			if e.suppressed > 0 && (next.IsZero() || expires.Before(next)) {
+				next = expires
+			}
+			continue
+		}
+		if e.suppressed > 0 {
+			flushed = append(flushed, repeated(k, e.suppressed))
+		}
+		delete(l.errors, k)
+	}
+	if !next.IsZero() {
+		l.flushTimer = time.AfterFunc(next.Sub(now), l.flushErrors)
+	}
+	l.mu.Unlock()
+
+	sort.Strings(flushed)
+	for _, f := range flushed {
+		l.print(wlog.ERROR, f)
+	}
+}
+
+// repeated returns the error message with the number of times it was
+// suppressed, if any.
+func repeated(msg string, suppressed int) string {
+	if suppressed == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s (repeated %d times)", msg, suppressed)
+}
+
+// print logs a message if the level is enabled for the plugin.
+func (l *Logger) print(level wlog.Level, msg string) {
+	if l.Level != 0 && level < l.Level {
+		return
+	}
+
+	line := levelPrefix[level] + " [" + l.Name + "] " + msg
+	switch {
+	case l.Output != nil:
+		fmt.Fprintln(l.Output, line)
+	case l.Level != 0:
+		fmt.Fprintln(logger.DirectWriter(), line)
+	default:
+		log.Print(line)
+	}
+}
+
+var levelPrefix = map[wlog.Level]string{
+	wlog.DEBUG: "D!",
+	wlog.INFO:  "I!",
+	wlog.WARN:  "W!",
+	wlog.ERROR: "E!",
+}
+
+// newLogger returns the logger of a plugin with the given log configuration.
+func newLogger(pluginType, name, alias string, errs selfstat.Stat, config LogConfig) *Logger {
+	l := &Logger{
+		Name:      logName(pluginType, name, alias),
+		Errs:      errs,
+		RateLimit: config.RateLimit,
+	}
+
+	if config.Level != "" {
+		level, err := logger.ParseLevel(config.Level)
+		if err != nil {
+			log.Printf("E! [%s] %v", l.Name, err)
+		} else {
+			l.Level = level
+		}
+	}
+
+	if config.Logfile != "" {
+		w, err := logger.PluginFileWriter(config.Logfile, config.LogFormat,
+			config.LogfileRotationInterval, config.LogfileRotationMaxSize,
+			config.LogfileRotationMaxArchives)
+		if err != nil {
+			log.Printf("E! [%s] Unable to open %s (%s), using agent log",
+				l.Name, config.Logfile, err)
+		} else {
+			l.Output = w
+		}
+	}
+
+	return l
 }
 
 // logName returns the log-friendly name/type.
@@ -1,9 +1,12 @@
 package models
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/wlog"
 	"github.com/stretchr/testify/require"
 )
 
@@ -68,3 +71,87 @@ func TestLogging(t *testing.T) {
 	log.Error("something happened")
 	require.Equal(t, int64(2), log.Errs.Get())
 }
+
+func TestLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := Logger{
+		Name:   "inputs.test",
+		Errs:   selfstat.Register("gather", "errors", map[string]string{"input": "test"}),
+		Level:  wlog.WARN,
+		Output: &buf,
+	}
+
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+	log.Errorf("error %d", 42)
+
+	require.Equal(t, "W! [inputs.test] warn\nE! [inputs.test] error 42\n", buf.String())
+}
+
+func TestLogRateLimit(t *testing.T) {
+	var buf bytes.Buffer
+	log := Logger{
+		Name:      "inputs.test",
+		Errs:      selfstat.Register("gather", "errors", map[string]string{"input": "test"}),
+		RateLimit: time.Hour,
+		Output:    &buf,
+	}
+	log.Errs.Set(0)
+
+	log.Error("connection refused")
+	log.Error("connection refused")
+	log.Error("timeout")
+	log.Error("connection refused")
+
+	require.Equal(t, int64(4), log.Errs.Get())
+	require.Equal(t, "E! [inputs.test] connection refused\nE! [inputs.test] timeout\n", buf.String())
+
+	// Once the rate limit expires the number of suppressed errors is logged.
+	log.errors["connection refused"].logged = time.Now().Add(-2 * time.Hour)
+	buf.Reset()
+	log.Error("connection refused")
+	require.Equal(t, "E! [inputs.test] connection refused (repeated 2 times)\n", buf.String())
+
+	// Other expired errors report their suppressed count instead of losing
+	// it.
+	log.Error("timeout")
+	log.Error("timeout")
+	log.errors["timeout"].logged = time.Now().Add(-2 * time.Hour)
+	buf.Reset()
+	log.Error("disk full")
+	require.Equal(t, "E! [inputs.test] timeout (repeated 2 times)\nE! [inputs.test] disk full\n", buf.String())
+	require.NotContains(t, log.errors, "timeout")
+}
+
+// lineWriter sends each line written to a channel.
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestLogRateLimitFlush(t *testing.T) {
+	lines := make(lineWriter, 10)
+	log := Logger{
+		Name:      "inputs.test",
+		Errs:      selfstat.Register("gather", "errors", map[string]string{"input": "test"}),
+		RateLimit: 50 * time.Millisecond,
+		Output:    lines,
+	}
+
+	log.Error("connection refused")
+	log.Error("connection refused")
+	log.Error("connection refused")
+	require.Equal(t, "E! [inputs.test] connection refused\n", <-lines)
+
+	// The suppressed count is logged once the rate limit expires, even if
+	// the error is not logged again.
+	select {
+	case line := <-lines:
+		require.Equal(t, "E! [inputs.test] connection refused (repeated 2 times)\n", line)
+	case <-time.After(time.Second):
+		require.Fail(t, "suppressed count not logged")
+	}
+}
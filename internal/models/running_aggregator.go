@@ -29,10 +29,8 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 		tags["alias"] = config.Alias
 	}
 
-	logger := &Logger{
-		Name: logName("aggregators", config.Name, config.Alias),
-		Errs: selfstat.Register("aggregate", "errors", tags),
-	}
+	logger := newLogger("aggregators", config.Name, config.Alias,
+		selfstat.Register("aggregate", "errors", tags), config.Log)
 
 	setLogIfExist(aggregator, logger)
 
@@ -77,12 +75,17 @@ type AggregatorConfig struct {
 	MeasurementSuffix string
 	Tags              map[string]string
 	Filter            Filter
+	Log               LogConfig
 }
 
 func (r *RunningAggregator) LogName() string {
 	return logName("aggregators", r.Config.Name, r.Config.Alias)
 }
 
+func (r *RunningAggregator) Log() telegraf.Logger {
+	return r.log
+}
+
 func (r *RunningAggregator) Init() error {
 	if p, ok := r.Aggregator.(telegraf.Initializer); ok {
 		err := p.Init()
@@ -141,6 +144,7 @@ func (r *RunningAggregator) Add(m telegraf.Metric) bool {
 	r.Config.Filter.Modify(m)
 	if len(m.FieldList()) == 0 {
 		r.MetricsFiltered.Incr(1)
+		r.traceAdd(m, "not aggregated, no fields left after filter")
 		return r.Config.DropOriginal
 	}
 
@@ -151,13 +155,23 @@ func (r *RunningAggregator) Add(m telegraf.Metric) bool {
 		r.log.Debugf("Metric is outside aggregation window; discarding. %s: m: %s e: %s g: %s",
 			m.Time(), r.periodStart, r.periodEnd, r.Config.Grace)
 		r.MetricsDropped.Incr(1)
+		r.traceAdd(m, "not aggregated, outside of the aggregation period")
 		return r.Config.DropOriginal
 	}
 
 	r.Aggregator.Add(m)
+	r.traceAdd(m, "aggregated")
 	return r.Config.DropOriginal
 }
 
+// traceAdd records the result of adding a traced metric.
+func (r *RunningAggregator) traceAdd(m telegraf.Metric, stage string) {
+	if r.Config.DropOriginal {
+		stage += ", original dropped"
+	}
+	Trace(m, r.LogName(), stage)
+}
+
 func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	r.Lock()
 	defer r.Unlock()
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"math"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -10,14 +13,27 @@ import (
 var GlobalMetricsGathered = selfstat.Register("agent", "metrics_gathered", map[string]string{})
 
 type RunningInput struct {
+	// Must be 64-bit aligned
+	intervalMetrics int64
+
 	Input  telegraf.Input
 	Config *InputConfig
 
-	log         telegraf.Logger
+	paused      int32
+	limitWarned int32
+
+	log         *Logger
 	defaultTags map[string]string
 
+	mu             sync.Mutex
+	lastGather     time.Time
+	lastGatherTime time.Duration
+
 	MetricsGathered selfstat.Stat
+	MetricsSampled  selfstat.Stat
+	MetricsLimited  selfstat.Stat
 	GatherTime      selfstat.Stat
+	GatherErrors    selfstat.Stat
 }
 
 func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
@@ -26,10 +42,9 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 		tags["alias"] = config.Alias
 	}
 
-	logger := &Logger{
-		Name: logName("inputs", config.Name, config.Alias),
-		Errs: selfstat.Register("gather", "errors", tags),
-	}
+	// Errors added to the accumulator are logged, the logger counts them.
+	gatherErrors := selfstat.Register("gather", "errors", tags)
+	logger := newLogger("inputs", config.Name, config.Alias, gatherErrors, config.Log)
 	setLogIfExist(input, logger)
 
 	return &RunningInput{
@@ -40,12 +55,23 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 			"metrics_gathered",
 			tags,
 		),
-		GatherTime: selfstat.RegisterTiming(
+		MetricsSampled: selfstat.Register(
+			"gather",
+			"metrics_dropped_sampling",
+			tags,
+		),
+		MetricsLimited: selfstat.Register(
+			"gather",
+			"metrics_dropped_limit",
+			tags,
+		),
+		GatherTime: selfstat.RegisterHistogram(
 			"gather",
 			"gather_time_ns",
 			tags,
 		),
-		log: logger,
+		GatherErrors: gatherErrors,
+		log:          logger,
 	}
 }
 
@@ -60,6 +86,14 @@ type InputConfig struct {
 	MeasurementSuffix string
 	Tags              map[string]string
 	Filter            Filter
+	Log               LogConfig
+
+	// MaxMetricsPerInterval is the maximum number of metrics accepted from
+	// the input each interval, 0 for no limit.
+	MaxMetricsPerInterval int
+	// SampleRatio is the ratio of the series of the input that are kept, 0
+	// to keep all series.
+	SampleRatio float64
 }
 
 func (r *RunningInput) metricFiltered(metric telegraf.Metric) {
@@ -70,6 +104,10 @@ func (r *RunningInput) LogName() string {
 	return logName("inputs", r.Config.Name, r.Config.Alias)
 }
 
+func (r *RunningInput) Log() telegraf.Logger {
+	return r.log
+}
+
 func (r *RunningInput) Init() error {
 	if p, ok := r.Input.(telegraf.Initializer); ok {
 		err := p.Init()
@@ -81,7 +119,17 @@ func (r *RunningInput) Init() error {
 }
 
 func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
+	metric = traceStart(metric, r.LogName())
+
+	// Service inputs keep running while paused, their metrics are dropped.
+	if r.Paused() {
+		Trace(metric, r.LogName(), "dropped, input is paused")
+		r.metricFiltered(metric)
+		return nil
+	}
+
 	if ok := r.Config.Filter.Select(metric); !ok {
+		Trace(metric, r.LogName(), "dropped by filter")
 		r.metricFiltered(metric)
 		return nil
 	}
@@ -96,23 +144,106 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 
 	r.Config.Filter.Modify(metric)
 	if len(metric.FieldList()) == 0 {
+		Trace(metric, r.LogName(), "dropped, no fields left after filter")
 		r.metricFiltered(metric)
 		return nil
 	}
 
+	if !r.sampled(m) {
+		Trace(m, r.LogName(), "dropped by sampling")
+		r.MetricsSampled.Incr(1)
+		r.metricFiltered(m)
+		return nil
+	}
+
+	if !r.allowed() {
+		Trace(m, r.LogName(), "dropped, max_metrics_per_interval reached")
+		r.MetricsLimited.Incr(1)
+		r.metricFiltered(m)
+		return nil
+	}
+
 	r.MetricsGathered.Incr(1)
 	GlobalMetricsGathered.Incr(1)
 	return m
 }
 
+// sampled returns true if the series of the metric is kept by sampling.  The
+// same series are always kept so that the kept series have no gaps.
+func (r *RunningInput) sampled(metric telegraf.Metric) bool {
+	ratio := r.Config.SampleRatio
+	if ratio <= 0 || ratio >= 1 {
+		return true
+	}
+	return float64(metric.HashID()) < ratio*math.MaxUint64
+}
+
+// allowed returns true if the metric is within the maximum number of metrics
+// of the interval.  A warning is logged the first time metrics are dropped,
+// the dropped metrics are counted in metrics_dropped_limit.
+func (r *RunningInput) allowed() bool {
+	max := r.Config.MaxMetricsPerInterval
+	if max <= 0 {
+		return true
+	}
+	if atomic.AddInt64(&r.intervalMetrics, 1) <= int64(max) {
+		return true
+	}
+	if atomic.CompareAndSwapInt32(&r.limitWarned, 0, 1) {
+		r.log.Warnf("More than %d metrics gathered in an interval, dropping the "+
+			"excess metrics; further drops are counted in internal_gather "+
+			"metrics_dropped_limit", max)
+	}
+	return false
+}
+
 func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
+	if r.Paused() {
+		r.log.Debugf("Skipping gather, input is paused")
+		return nil
+	}
+
+	atomic.StoreInt64(&r.intervalMetrics, 0)
+
 	start := time.Now()
 	err := r.Input.Gather(acc)
 	elapsed := time.Since(start)
 	r.GatherTime.Incr(elapsed.Nanoseconds())
+
+	r.mu.Lock()
+	r.lastGather = start
+	r.lastGatherTime = elapsed
+	r.mu.Unlock()
 	return err
 }
 
+// LastGather returns the start time and duration of the last gather.
+func (r *RunningInput) LastGather() (time.Time, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastGather, r.lastGatherTime
+}
+
+// LastError returns the last error logged by the input.
+func (r *RunningInput) LastError() (time.Time, string) {
+	return r.log.LastError()
+}
+
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
 	r.defaultTags = tags
 }
+
+// Pause stops the input from gathering until it is resumed.
+func (r *RunningInput) Pause() {
+	atomic.StoreInt32(&r.paused, 1)
+}
+
+// Resume restarts gathering of a paused input.
+func (r *RunningInput) Resume() {
+	atomic.StoreInt32(&r.paused, 0)
+}
+
+// Paused returns true if the input is paused.
+func (r *RunningInput) Paused() bool {
+	return atomic.LoadInt32(&r.paused) == 1
+}
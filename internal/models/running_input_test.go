@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -256,6 +257,93 @@ func TestMakeMetricNameSuffix(t *testing.T) {
 	require.Equal(t, expected, m)
 }
 
+func TestRunningInputPaused(t *testing.T) {
+	input := &countingInput{}
+	ri := NewRunningInput(input, &InputConfig{
+		Name: "TestRunningInput",
+	})
+
+	acc := &testutil.Accumulator{}
+	ri.Pause()
+	require.NoError(t, ri.Gather(acc))
+	assert.Equal(t, 0, input.gathers)
+
+	m, err := metric.New("RITest",
+		map[string]string{},
+		map[string]interface{}{"value": 1},
+		time.Now(),
+		telegraf.Untyped)
+	require.NoError(t, err)
+	assert.Nil(t, ri.MakeMetric(m))
+
+	ri.Resume()
+	require.NoError(t, ri.Gather(acc))
+	assert.Equal(t, 1, input.gathers)
+}
+
+func TestRunningInputMaxMetricsPerInterval(t *testing.T) {
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name:                  "TestRunningInputMaxMetrics",
+		MaxMetricsPerInterval: 2,
+	})
+
+	for i := 0; i < 3; i++ {
+		m := ri.MakeMetric(testutil.TestMetric(i))
+		if i < 2 {
+			require.NotNil(t, m)
+		} else {
+			require.Nil(t, m)
+		}
+	}
+	require.Equal(t, int64(1), ri.MetricsLimited.Get())
+
+	// The limit is reset each interval.
+	require.NoError(t, ri.Gather(&testutil.Accumulator{}))
+	require.NotNil(t, ri.MakeMetric(testutil.TestMetric(3)))
+}
+
+func TestRunningInputSampleRatio(t *testing.T) {
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name:        "TestRunningInputSampleRatio",
+		SampleRatio: 0.5,
+	})
+
+	kept := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		host := fmt.Sprintf("host%d", i)
+		m := ri.MakeMetric(testutil.MustMetric("cpu",
+			map[string]string{"host": host},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0)))
+		if m != nil {
+			kept[host] = true
+		}
+	}
+	require.InDelta(t, 500, len(kept), 100)
+	require.Equal(t, int64(1000-len(kept)), ri.MetricsSampled.Get())
+
+	// The same series are kept each time.
+	for i := 0; i < 1000; i++ {
+		host := fmt.Sprintf("host%d", i)
+		m := ri.MakeMetric(testutil.MustMetric("cpu",
+			map[string]string{"host": host},
+			map[string]interface{}{"value": 43},
+			time.Unix(10, 0)))
+		require.Equal(t, kept[host], m != nil)
+	}
+}
+
+type countingInput struct {
+	gathers int
+}
+
+func (t *countingInput) Description() string  { return "" }
+func (t *countingInput) SampleConfig() string { return "" }
+func (t *countingInput) Gather(acc telegraf.Accumulator) error {
+	t.gathers++
+	return nil
+}
+
 type testInput struct{}
 
 func (t *testInput) Description() string                   { return "" }
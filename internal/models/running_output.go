@@ -1,11 +1,13 @@
 package models
 
 import (
+	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -27,6 +29,17 @@ type OutputConfig struct {
 	FlushJitter       *time.Duration
 	MetricBufferLimit int
 	MetricBatchSize   int
+
+	// IdempotencyTag is the tag set to the idempotency ID of the metrics,
+	// letting receivers discard metrics sent again by a retried write.
+	IdempotencyTag string
+
+	Log LogConfig
+}
+
+// Serializer serializes a batch of metrics, in the data format of an output.
+type Serializer interface {
+	SerializeBatch(metrics []telegraf.Metric) ([]byte, error)
 }
 
 // RunningOutput contains the output configuration
@@ -34,6 +47,7 @@ type RunningOutput struct {
 	// Must be 64-bit aligned
 	newMetricsCount int64
 	droppedMetrics  int64
+	paused          int32
 
 	Output            telegraf.Output
 	Config            *OutputConfig
@@ -42,13 +56,30 @@ type RunningOutput struct {
 
 	MetricsFiltered selfstat.Stat
 	WriteTime       selfstat.Stat
+	WriteErrors     selfstat.Stat
+	WriteRetries    selfstat.Stat
 
 	BatchReady chan time.Time
 
+	// Serializer shows the data the output would write in test mode, for
+	// outputs which do not implement DryRun.
+	Serializer Serializer
+
 	buffer *Buffer
-	log    telegraf.Logger
+	log    *Logger
 
 	aggMutex sync.Mutex
+
+	writeMutex sync.Mutex
+	lastWrite  WriteResult
+}
+
+// WriteResult is the result of a write of a batch to the output.
+type WriteResult struct {
+	Time     time.Time
+	Duration time.Duration
+	Metrics  int
+	Err      error
 }
 
 func NewRunningOutput(
@@ -63,10 +94,8 @@ func NewRunningOutput(
 		tags["alias"] = config.Alias
 	}
 
-	logger := &Logger{
-		Name: logName("outputs", config.Name, config.Alias),
-		Errs: selfstat.Register("write", "errors", tags),
-	}
+	logger := newLogger("outputs", config.Name, config.Alias,
+		selfstat.Register("write", "errors", tags), config.Log)
 	setLogIfExist(output, logger)
 
 	if config.MetricBufferLimit > 0 {
@@ -94,11 +123,21 @@ func NewRunningOutput(
 			"metrics_filtered",
 			tags,
 		),
-		WriteTime: selfstat.RegisterTiming(
+		WriteTime: selfstat.RegisterHistogram(
 			"write",
 			"write_time_ns",
 			tags,
 		),
+		WriteErrors: selfstat.Register(
+			"write",
+			"write_errors",
+			tags,
+		),
+		WriteRetries: selfstat.Register(
+			"write",
+			"write_retries",
+			tags,
+		),
 		log: logger,
 	}
 
@@ -130,23 +169,27 @@ func (r *RunningOutput) Init() error {
 // Takes ownership of metric
 func (ro *RunningOutput) AddMetric(metric telegraf.Metric) {
 	if ok := ro.Config.Filter.Select(metric); !ok {
+		Trace(metric, ro.LogName(), "dropped by filter")
 		ro.metricFiltered(metric)
 		return
 	}
 
 	ro.Config.Filter.Modify(metric)
 	if len(metric.FieldList()) == 0 {
+		Trace(metric, ro.LogName(), "dropped, no fields left after filter")
 		ro.metricFiltered(metric)
 		return
 	}
 
 	if output, ok := ro.Output.(telegraf.AggregatingOutput); ok {
+		Trace(metric, ro.LogName(), "added to output aggregation")
 		ro.aggMutex.Lock()
 		output.Add(metric)
 		ro.aggMutex.Unlock()
 		return
 	}
 
+	ro.setIdempotencyID(metric)
 	dropped := ro.buffer.Add(metric)
 	atomic.AddInt64(&ro.droppedMetrics, int64(dropped))
 
@@ -160,12 +203,40 @@ func (ro *RunningOutput) AddMetric(metric telegraf.Metric) {
 	}
 }
 
+// setIdempotencyID tags the metric with its idempotency ID if enabled.  An ID
+// set by an upstream agent is kept.
+func (ro *RunningOutput) setIdempotencyID(m telegraf.Metric) {
+	tag := ro.Config.IdempotencyTag
+	if tag != "" && !m.HasTag(tag) {
+		m.AddTag(tag, metric.IdempotencyID(m))
+	}
+}
+
 // Write writes all metrics to the output, stopping when all have been sent on
 // or error.
 func (ro *RunningOutput) Write() error {
+	if ro.Paused() {
+		return nil
+	}
+	return ro.writeAll()
+}
+
+// Flush writes all metrics to the output even if it is paused, for the last
+// write before shutdown which would otherwise drop the buffered metrics.
+func (ro *RunningOutput) Flush() error {
+	if ro.Paused() {
+		ro.log.Infof("Output is paused, writing %d buffered metrics before shutdown", ro.buffer.Len())
+	}
+	return ro.writeAll()
+}
+
+func (ro *RunningOutput) writeAll() error {
 	if output, ok := ro.Output.(telegraf.AggregatingOutput); ok {
 		ro.aggMutex.Lock()
 		metrics := output.Push()
+		for _, m := range metrics {
+			ro.setIdempotencyID(m)
+		}
 		ro.buffer.Add(metrics...)
 		output.Reset()
 		ro.aggMutex.Unlock()
@@ -185,7 +256,7 @@ func (ro *RunningOutput) Write() error {
 
 		err := ro.write(batch)
 		if err != nil {
-			ro.buffer.Reject(batch)
+			ro.release(batch, err)
 			return err
 		}
 		ro.buffer.Accept(batch)
@@ -195,6 +266,10 @@ func (ro *RunningOutput) Write() error {
 
 // WriteBatch writes a single batch of metrics to the output.
 func (ro *RunningOutput) WriteBatch() error {
+	if ro.Paused() {
+		return nil
+	}
+
 	batch := ro.buffer.Batch(ro.MetricBatchSize)
 	if len(batch) == 0 {
 		return nil
@@ -202,7 +277,7 @@ func (ro *RunningOutput) WriteBatch() error {
 
 	err := ro.write(batch)
 	if err != nil {
-		ro.buffer.Reject(batch)
+		ro.release(batch, err)
 		return err
 	}
 	ro.buffer.Accept(batch)
@@ -210,6 +285,93 @@ func (ro *RunningOutput) WriteBatch() error {
 	return nil
 }
 
+// DryRun returns the data the output would write for the metrics without
+// writing them, applying the output filters, aggregation and idempotency
+// tag.  The data is returned by the output if it implements DryRun, and by the
+// Serializer otherwise.
+//
+// Takes ownership of the metrics
+func (ro *RunningOutput) DryRun(metrics []telegraf.Metric) ([]byte, error) {
+	selected := make([]telegraf.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		if ok := ro.Config.Filter.Select(metric); !ok {
+			metric.Drop()
+			continue
+		}
+
+		ro.Config.Filter.Modify(metric)
+		if len(metric.FieldList()) == 0 {
+			metric.Drop()
+			continue
+		}
+		selected = append(selected, metric)
+	}
+
+	if output, ok := ro.Output.(telegraf.AggregatingOutput); ok {
+		ro.aggMutex.Lock()
+		for _, metric := range selected {
+			output.Add(metric)
+		}
+		selected = output.Push()
+		output.Reset()
+		ro.aggMutex.Unlock()
+	}
+	for _, metric := range selected {
+		ro.setIdempotencyID(metric)
+	}
+
+	if output, ok := ro.Output.(telegraf.DryRunOutput); ok {
+		return output.DryRun(selected)
+	}
+	if ro.Serializer == nil {
+		return nil, errors.New("output has no data format to show the metrics in")
+	}
+	return ro.Serializer.SerializeBatch(selected)
+}
+
+// release returns the batch to the buffer after a failed write.  When the
+// output reports a partial write, the metrics already sent are accepted and
+// only the remaining metrics are retried.
+func (ro *RunningOutput) release(batch []telegraf.Metric, err error) {
+	if perr, ok := err.(*telegraf.PartialWriteError); ok {
+		written := max(0, min(perr.Written, len(batch)))
+		ro.log.Debugf("Wrote %d of %d metrics before error, retrying the remaining metrics",
+			written, len(batch))
+		ro.traceReject(batch[written:], err)
+		ro.buffer.AcceptPartial(batch, written)
+		return
+	}
+
+	ro.traceReject(batch, err)
+	ro.buffer.Reject(batch)
+}
+
+// traceReject records the failed write of the traced metrics of the batch.
+func (ro *RunningOutput) traceReject(batch []telegraf.Metric, err error) {
+	if !TracingEnabled() {
+		return
+	}
+	for _, m := range batch {
+		Tracef(m, ro.LogName(), "write failed, returned to buffer: %v", err)
+	}
+}
+
+// Pause stops writing to the output until it is resumed, metrics are kept in
+// the buffer in the meantime.
+func (ro *RunningOutput) Pause() {
+	atomic.StoreInt32(&ro.paused, 1)
+}
+
+// Resume restarts writing to a paused output.
+func (ro *RunningOutput) Resume() {
+	atomic.StoreInt32(&ro.paused, 0)
+}
+
+// Paused returns true if the output is paused.
+func (ro *RunningOutput) Paused() bool {
+	return atomic.LoadInt32(&ro.paused) == 1
+}
+
 func (r *RunningOutput) Close() {
 	err := r.Output.Close()
 	if err != nil {
@@ -228,6 +390,22 @@ func (r *RunningOutput) write(metrics []telegraf.Metric) error {
 	err := r.Output.Write(metrics)
 	elapsed := time.Since(start)
 	r.WriteTime.Incr(elapsed.Nanoseconds())
+	if err != nil {
+		r.WriteErrors.Incr(1)
+	}
+
+	r.writeMutex.Lock()
+	// Rejected metrics are retried on the following write.
+	if r.lastWrite.Err != nil {
+		r.WriteRetries.Incr(1)
+	}
+	r.lastWrite = WriteResult{
+		Time:     start,
+		Duration: elapsed,
+		Metrics:  len(metrics),
+		Err:      err,
+	}
+	r.writeMutex.Unlock()
 
 	if err == nil {
 		r.log.Debugf("Wrote batch of %d metrics in %s", len(metrics), elapsed)
@@ -235,7 +413,28 @@ func (r *RunningOutput) write(metrics []telegraf.Metric) error {
 	return err
 }
 
+// LastWrite returns the result of the last write to the output.
+func (r *RunningOutput) LastWrite() WriteResult {
+	r.writeMutex.Lock()
+	defer r.writeMutex.Unlock()
+	return r.lastWrite
+}
+
+// LastError returns the last error logged by the output.
+func (r *RunningOutput) LastError() (time.Time, string) {
+	return r.log.LastError()
+}
+
+// BufferLen returns the number of metrics in the buffer.
+func (r *RunningOutput) BufferLen() int {
+	return r.buffer.Len()
+}
+
 func (r *RunningOutput) LogBufferStatus() {
 	nBuffer := r.buffer.Len()
+	if r.Paused() {
+		r.log.Debugf("Output is paused, buffer fullness: %d / %d metrics", nBuffer, r.MetricBufferLimit)
+		return
+	}
 	r.log.Debugf("Buffer fullness: %d / %d metrics", nBuffer, r.MetricBufferLimit)
 }
@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/plugins/serializers/json"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -107,6 +111,40 @@ func TestRunningOutput_DropFilter(t *testing.T) {
 	assert.Len(t, m.Metrics(), 8)
 }
 
+// Test that a paused output keeps the metrics in its buffer.
+func TestRunningOutput_Paused(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 1000, 10000)
+	ro.Pause()
+	assert.True(t, ro.Paused())
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	assert.NoError(t, ro.Write())
+	assert.NoError(t, ro.WriteBatch())
+	assert.Len(t, m.Metrics(), 0)
+
+	ro.Resume()
+	assert.False(t, ro.Paused())
+	assert.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 5)
+
+	// The metrics of a paused output are written before shutdown.
+	ro.Pause()
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+	assert.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 5)
+	assert.NoError(t, ro.Flush())
+	assert.Len(t, m.Metrics(), 10)
+}
+
 // Test that NameDrop filters without a match do nothing.
 func TestRunningOutput_PassFilter(t *testing.T) {
 	conf := &OutputConfig{
@@ -308,6 +346,30 @@ func TestRunningOutputWriteFailOrder(t *testing.T) {
 	assert.Equal(t, expected, m.Metrics())
 }
 
+// Verify that write errors and retries are counted.
+func TestRunningOutputWriteErrorsAndRetries(t *testing.T) {
+	conf := &OutputConfig{
+		Name:   "retries",
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("retries", m, conf, 100, 1000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	require.Error(t, ro.Write())
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	require.NoError(t, ro.Write())
+
+	assert.Equal(t, int64(2), ro.WriteErrors.Get())
+	assert.Equal(t, int64(2), ro.WriteRetries.Get())
+}
+
 // Verify that the order of points is preserved during many write failures.
 func TestRunningOutputWriteFailOrder2(t *testing.T) {
 	conf := &OutputConfig{
@@ -412,6 +474,135 @@ func TestRunningOutputWriteFailOrder3(t *testing.T) {
 	assert.Equal(t, expected, m.Metrics())
 }
 
+func TestRunningOutputDryRun(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{
+			NameDrop: []string{"metric1", "metric2"},
+		},
+	}
+	require.NoError(t, conf.Filter.Compile())
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 1000, 10000)
+
+	// Without a serializer the data cannot be shown.
+	_, err := ro.DryRun([]telegraf.Metric{testutil.TestMetric(101, "metric3")})
+	require.Error(t, err)
+
+	ro.Serializer = influx.NewSerializer()
+	octets, err := ro.DryRun([]telegraf.Metric{
+		testutil.TestMetric(101, "metric1"),
+		testutil.TestMetric(101, "metric3"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "metric3,tag1=value1 value=101i 1257894000000000000\n", string(octets))
+	assert.Len(t, m.Metrics(), 0)
+
+	serializer, err := json.NewSerializer(time.Second)
+	require.NoError(t, err)
+	ro.Serializer = serializer
+	octets, err = ro.DryRun([]telegraf.Metric{testutil.TestMetric(101, "metric3")})
+	require.NoError(t, err)
+	assert.Equal(t, `{"metrics":[{"fields":{"value":101},"name":"metric3","tags":{"tag1":"value1"},"timestamp":1257894000}]}`,
+		string(octets))
+
+	ro = NewRunningOutput("test", &dryRunOutput{}, &OutputConfig{}, 1000, 10000)
+	octets, err = ro.DryRun([]telegraf.Metric{
+		testutil.TestMetric(101, "metric1"),
+		testutil.TestMetric(101, "metric3"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "metric1\nmetric3\n", string(octets))
+}
+
+// Verify that only the metrics not written are retried after a partial write.
+func TestRunningOutputPartialWrite(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{failAfter: 2}
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	err := ro.Write()
+	require.Error(t, err)
+	require.Len(t, m.Metrics(), 2)
+	require.Equal(t, 3, ro.buffer.Len())
+
+	m.failAfter = 0
+	err = ro.Write()
+	require.NoError(t, err)
+
+	// Each metric is written once.
+	testutil.RequireMetricsEqual(t, reverse(first5), m.Metrics())
+}
+
+func TestRunningOutputIdempotencyTag(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:         Filter{},
+		IdempotencyTag: "id",
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+
+	ro.AddMetric(testutil.TestMetric(101, "metric1"))
+	ro.AddMetric(testutil.TestMetric(101, "metric2"))
+	ro.AddMetric(testutil.TestMetric(102, "metric1"))
+	upstream := testutil.TestMetric(101, "metric1")
+	upstream.AddTag("id", "upstream")
+	ro.AddMetric(upstream)
+	other := testutil.TestMetric(101, "metric1")
+	other.RemoveField("value")
+	other.AddField("other", 101)
+	ro.AddMetric(other)
+
+	err := ro.Write()
+	require.NoError(t, err)
+
+	ids := make(map[string]bool)
+	for _, out := range m.Metrics() {
+		id, ok := out.GetTag("id")
+		require.True(t, ok)
+		ids[id] = true
+	}
+	// Field values are not part of the ID, only the series, field keys and
+	// timestamp.
+	require.Len(t, ids, 4)
+	require.True(t, ids["upstream"])
+	require.True(t, ids[metric.IdempotencyID(testutil.TestMetric(42, "metric1"))])
+}
+
+func TestRunningOutputDryRunIdempotencyTag(t *testing.T) {
+	conf := &OutputConfig{
+		IdempotencyTag: "id",
+	}
+	ro := NewRunningOutput("test", &mockOutput{}, conf, 1000, 10000)
+	ro.Serializer = influx.NewSerializer()
+
+	m := testutil.TestMetric(101, "metric1")
+	id := metric.IdempotencyID(m)
+	octets, err := ro.DryRun([]telegraf.Metric{m})
+	require.NoError(t, err)
+	assert.Equal(t, "metric1,id="+id+",tag1=value1 value=101i 1257894000000000000\n", string(octets))
+}
+
+type dryRunOutput struct {
+	mockOutput
+}
+
+func (m *dryRunOutput) DryRun(metrics []telegraf.Metric) ([]byte, error) {
+	var octets []byte
+	for _, metric := range metrics {
+		octets = append(octets, metric.Name()+"\n"...)
+	}
+	return octets, nil
+}
+
 type mockOutput struct {
 	sync.Mutex
 
@@ -419,6 +610,8 @@ type mockOutput struct {
 
 	// if true, mock a write failure
 	failWrite bool
+	// if > 0, mock a write failure after writing this many metrics
+	failAfter int
 }
 
 func (m *mockOutput) Connect() error {
@@ -448,7 +641,10 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 		m.metrics = []telegraf.Metric{}
 	}
 
-	for _, metric := range metrics {
+	for i, metric := range metrics {
+		if m.failAfter > 0 && i == m.failAfter {
+			return &telegraf.PartialWriteError{Written: i, Err: fmt.Errorf("Failed Write!")}
+		}
 		m.metrics = append(m.metrics, metric)
 	}
 	return nil
@@ -26,6 +26,7 @@ type ProcessorConfig struct {
 	Alias  string
 	Order  int64
 	Filter Filter
+	Log    LogConfig
 }
 
 func NewRunningProcessor(processor telegraf.Processor, config *ProcessorConfig) *RunningProcessor {
@@ -34,10 +35,8 @@ func NewRunningProcessor(processor telegraf.Processor, config *ProcessorConfig)
 		tags["alias"] = config.Alias
 	}
 
-	logger := &Logger{
-		Name: logName("processors", config.Name, config.Alias),
-		Errs: selfstat.Register("process", "errors", tags),
-	}
+	logger := newLogger("processors", config.Name, config.Alias,
+		selfstat.Register("process", "errors", tags), config.Log)
 	setLogIfExist(processor, logger)
 
 	return &RunningProcessor{
@@ -47,6 +46,19 @@ func NewRunningProcessor(processor telegraf.Processor, config *ProcessorConfig)
 	}
 }
 
+func (rp *RunningProcessor) LogName() string {
+	return logName("processors", rp.Config.Name, rp.Config.Alias)
+}
+
+func (rp *RunningProcessor) Log() telegraf.Logger {
+	return rp.log
+}
+
+// MakeMetric returns the metric emitted by a streaming processor.
+func (rp *RunningProcessor) MakeMetric(metric telegraf.Metric) telegraf.Metric {
+	return metric
+}
+
 func (rp *RunningProcessor) metricFiltered(metric telegraf.Metric) {
 	metric.Drop()
 }
@@ -60,6 +72,38 @@ func containsMetric(item telegraf.Metric, metrics []telegraf.Metric) bool {
 	return false
 }
 
+// traceApply records the result of applying the processor to a traced metric.
+// Metrics created by the processor in place of the traced metric, rather than
+// copied from it, are traced with the same ID.
+func (rp *RunningProcessor) traceApply(traceID, name string, out []telegraf.Metric) {
+	var traced []telegraf.Metric
+	for _, m := range out {
+		if id, ok := TraceID(m); ok && id == traceID {
+			traced = append(traced, m)
+		}
+	}
+
+	if len(traced) == 0 && len(out) > 0 {
+		for i, m := range out {
+			if _, ok := TraceID(m); !ok {
+				out[i] = &tracedMetric{Metric: m, id: traceID}
+				traced = append(traced, out[i])
+			}
+		}
+	}
+
+	switch len(traced) {
+	case 0:
+		traceEvent(traceID, rp.LogName(), name, "dropped by processor")
+	case 1:
+		Trace(traced[0], rp.LogName(), "processed")
+	default:
+		for _, m := range traced {
+			Tracef(m, rp.LogName(), "processed into %d metrics", len(traced))
+		}
+	}
+}
+
 func (r *RunningProcessor) Init() error {
 	if p, ok := r.Processor.(telegraf.Initializer); ok {
 		err := p.Init()
@@ -86,13 +130,20 @@ func (rp *RunningProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
 
 		rp.Config.Filter.Modify(metric)
 		if len(metric.FieldList()) == 0 {
+			Trace(metric, rp.LogName(), "dropped, no fields left after filter")
 			rp.metricFiltered(metric)
 			continue
 		}
 
 		// This metric should pass through the filter, so call the filter Apply
 		// function and append results to the output slice.
-		ret = append(ret, rp.Processor.Apply(metric)...)
+		traceID, traced := TraceID(metric)
+
+		out := rp.Processor.Apply(metric)
+		if traced {
+			rp.traceApply(traceID, metric.Name(), out)
+		}
+		ret = append(ret, out...)
 	}
 
 	return ret
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	// traceEventsSize is the number of trace events kept for the control API.
+	traceEventsSize = 10000
+)
+
+// TraceEvent is a stage passed by a traced metric.
+type TraceEvent struct {
+	Time    time.Time `json:"time"`
+	TraceID string    `json:"trace_id"`
+	Plugin  string    `json:"plugin"`
+	Stage   string    `json:"stage"`
+	Metric  string    `json:"metric"`
+}
+
+// tracer records the trace events of the sampled metrics.
+type tracer struct {
+	enabled int32
+	// sampleRate holds the bits of the float64 sample rate.
+	sampleRate uint64
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	events []TraceEvent
+	next   int
+	full   bool
+}
+
+var metricTracer = &tracer{}
+
+// tracedMetric is a sampled metric with its trace ID.  The ID is kept off the
+// tags so that traced metrics do not form new series in the processors,
+// aggregators and outputs.
+type tracedMetric struct {
+	telegraf.Metric
+	id string
+}
+
+// Copy returns a copy of the metric with the same trace ID.
+func (m *tracedMetric) Copy() telegraf.Metric {
+	return &tracedMetric{Metric: m.Metric.Copy(), id: m.id}
+}
+
+// TraceID returns the trace ID of the metric, false if it is not traced.
+func TraceID(m telegraf.Metric) (string, bool) {
+	if t, ok := m.(*tracedMetric); ok {
+		return t.id, true
+	}
+	return "", false
+}
+
+// EnableTracing starts tracing the ratio of the gathered metrics given by
+// sampleRate, between 0 and 1, with a trace ID.  The stages passed by traced
+// metrics are logged and kept for the control API.  Tracing is disabled when
+// sampleRate is 0.
+func EnableTracing(sampleRate float64) {
+	metricTracer.mu.Lock()
+	defer metricTracer.mu.Unlock()
+
+	if sampleRate <= 0 {
+		atomic.StoreInt32(&metricTracer.enabled, 0)
+		metricTracer.events = nil
+		metricTracer.next = 0
+		metricTracer.full = false
+		return
+	}
+
+	atomic.StoreUint64(&metricTracer.sampleRate, math.Float64bits(sampleRate))
+	if metricTracer.rand == nil {
+		metricTracer.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if metricTracer.events == nil {
+		metricTracer.events = make([]TraceEvent, traceEventsSize)
+	}
+	atomic.StoreInt32(&metricTracer.enabled, 1)
+}
+
+// TracingEnabled returns true if metrics are traced.
+func TracingEnabled() bool {
+	return atomic.LoadInt32(&metricTracer.enabled) == 1
+}
+
+// Traces returns the recorded trace events, oldest first, of the trace ID or
+// of all traces if traceID is empty.
+func Traces(traceID string) []TraceEvent {
+	metricTracer.mu.Lock()
+	defer metricTracer.mu.Unlock()
+
+	var events []TraceEvent
+	if metricTracer.full {
+		events = append(events, metricTracer.events[metricTracer.next:]...)
+	}
+	events = append(events, metricTracer.events[:metricTracer.next]...)
+
+	result := make([]TraceEvent, 0)
+	for _, e := range events {
+		if traceID == "" || e.TraceID == traceID {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// traceStart returns the metric with a new trace ID if it is sampled.
+func traceStart(m telegraf.Metric, plugin string) telegraf.Metric {
+	if !TracingEnabled() {
+		return m
+	}
+	if _, ok := TraceID(m); ok {
+		return m
+	}
+
+	// The sampling uses the locked global source, the source of the tracer
+	// is only locked for the sampled metrics.
+	sampleRate := math.Float64frombits(atomic.LoadUint64(&metricTracer.sampleRate))
+	if rand.Float64() >= sampleRate {
+		return m
+	}
+
+	metricTracer.mu.Lock()
+	id := strconv.FormatUint(metricTracer.rand.Uint64(), 16)
+	metricTracer.mu.Unlock()
+
+	traced := &tracedMetric{Metric: m, id: id}
+	Trace(traced, plugin, "gathered")
+	return traced
+}
+
+// Trace records the stage passed by the metric if it is traced.
+func Trace(m telegraf.Metric, plugin, stage string) {
+	if !TracingEnabled() {
+		return
+	}
+	id, ok := TraceID(m)
+	if !ok {
+		return
+	}
+
+	traceEvent(id, plugin, m.Name(), stage)
+}
+
+// traceEvent records a stage of the trace.
+func traceEvent(traceID, plugin, name, stage string) {
+	event := TraceEvent{
+		Time:    time.Now(),
+		TraceID: traceID,
+		Plugin:  plugin,
+		Stage:   stage,
+		Metric:  name,
+	}
+	log.Printf("I! [trace] %s [%s] %s: %s", traceID, plugin, name, stage)
+
+	metricTracer.mu.Lock()
+	defer metricTracer.mu.Unlock()
+	if metricTracer.events == nil {
+		return
+	}
+	metricTracer.events[metricTracer.next] = event
+	metricTracer.next++
+	if metricTracer.next == len(metricTracer.events) {
+		metricTracer.next = 0
+		metricTracer.full = true
+	}
+}
+
+// Tracef records the stage passed by the metric if it is traced, patterned
+// after fmt.Sprintf.
+func Tracef(m telegraf.Metric, plugin, format string, args ...interface{}) {
+	if _, ok := TraceID(m); !ok {
+		return
+	}
+	Trace(m, plugin, fmt.Sprintf(format, args...))
+}
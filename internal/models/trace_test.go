@@ -0,0 +1,136 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func traceStages(events []TraceEvent) []string {
+	stages := make([]string, 0, len(events))
+	for _, e := range events {
+		stages = append(stages, e.Plugin+": "+e.Stage)
+	}
+	return stages
+}
+
+func TestTraceMetric(t *testing.T) {
+	EnableTracing(1)
+	defer EnableTracing(0)
+
+	ri := NewRunningInput(&testInput{}, &InputConfig{Name: "cpu"})
+	rp := NewRunningProcessor(&MockProcessor{
+		ApplyF: func(in ...telegraf.Metric) []telegraf.Metric { return in },
+	}, &ProcessorConfig{Name: "noop"})
+	output := &mockOutput{failWrite: true}
+	ro := NewRunningOutput("file", output, &OutputConfig{Name: "file"}, 10, 100)
+
+	m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	require.NoError(t, err)
+
+	hash := m.HashID()
+	m = ri.MakeMetric(m)
+	require.NotNil(t, m)
+	traceID, ok := TraceID(m)
+	require.True(t, ok)
+
+	// The trace ID is not a tag, so the series of the metric is unchanged.
+	assert.Empty(t, m.Tags())
+	assert.Equal(t, hash, m.HashID())
+	copyID, ok := TraceID(m.Copy())
+	require.True(t, ok)
+	assert.Equal(t, traceID, copyID)
+
+	for _, m := range rp.Apply(m) {
+		ro.AddMetric(m)
+	}
+	require.Error(t, ro.Write())
+	output.failWrite = false
+	require.NoError(t, ro.Write())
+
+	assert.Equal(t, []string{
+		"inputs.cpu: gathered",
+		"processors.noop: processed",
+		"outputs.file: added to buffer",
+		"outputs.file: write failed, returned to buffer: Failed Write!",
+		"outputs.file: written",
+	}, traceStages(Traces(traceID)))
+	assert.Len(t, Traces("unknown"), 0)
+}
+
+func TestTraceDropped(t *testing.T) {
+	EnableTracing(1)
+	defer EnableTracing(0)
+
+	rp := NewRunningProcessor(&MockProcessor{
+		ApplyF: func(in ...telegraf.Metric) []telegraf.Metric { return nil },
+	}, &ProcessorConfig{Name: "drop"})
+	filter := Filter{NameDrop: []string{"mem"}}
+	require.NoError(t, filter.Compile())
+	ri := NewRunningInput(&testInput{}, &InputConfig{Name: "mem", Filter: filter})
+
+	m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	require.NoError(t, err)
+	m = traceStart(m, "inputs.cpu")
+	traceID, _ := TraceID(m)
+	assert.Len(t, rp.Apply(m), 0)
+	assert.Equal(t, []string{
+		"inputs.cpu: gathered",
+		"processors.drop: dropped by processor",
+	}, traceStages(Traces(traceID)))
+
+	m, err = metric.New("mem", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, ri.MakeMetric(m))
+	events := Traces("")
+	require.NotEmpty(t, events)
+	traceID = events[len(events)-1].TraceID
+	assert.Equal(t, []string{
+		"inputs.mem: gathered",
+		"inputs.mem: dropped by filter",
+	}, traceStages(Traces(traceID)))
+}
+
+func TestTraceReplacedByProcessor(t *testing.T) {
+	EnableTracing(1)
+	defer EnableTracing(0)
+
+	rp := NewRunningProcessor(&MockProcessor{
+		ApplyF: func(in ...telegraf.Metric) []telegraf.Metric {
+			m, _ := metric.New("new", in[0].Tags(), in[0].Fields(), in[0].Time())
+			return []telegraf.Metric{m}
+		},
+	}, &ProcessorConfig{Name: "replace"})
+
+	m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	require.NoError(t, err)
+	m = traceStart(m, "inputs.cpu")
+	traceID, _ := TraceID(m)
+
+	out := rp.Apply(m)
+	require.Len(t, out, 1)
+	id, ok := TraceID(out[0])
+	require.True(t, ok)
+	assert.Equal(t, traceID, id)
+	assert.Equal(t, []string{
+		"inputs.cpu: gathered",
+		"processors.replace: processed",
+	}, traceStages(Traces(traceID)))
+}
+
+func TestTraceDisabled(t *testing.T) {
+	EnableTracing(0)
+
+	ri := NewRunningInput(&testInput{}, &InputConfig{Name: "cpu"})
+	m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	require.NoError(t, err)
+
+	m = ri.MakeMetric(m)
+	_, ok := TraceID(m)
+	assert.False(t, ok)
+	assert.Len(t, Traces(""), 0)
+}
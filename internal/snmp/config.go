@@ -0,0 +1,35 @@
+package snmp
+
+import (
+	"github.com/influxdata/telegraf/internal"
+)
+
+// ClientConfig holds the options of the connections to SNMP agents.
+type ClientConfig struct {
+	// Timeout to wait for a response.
+	Timeout internal.Duration `toml:"timeout"`
+	Retries int               `toml:"retries"`
+	// Values: 1, 2, 3
+	Version uint8 `toml:"version"`
+
+	// Parameters for Version 1 & 2
+	Community string `toml:"community"`
+
+	// Parameters for Version 2 & 3
+	MaxRepetitions uint8 `toml:"max_repetitions"`
+
+	// Parameters for Version 3
+	ContextName string `toml:"context_name"`
+	// Values: "noAuthNoPriv", "authNoPriv", "authPriv"
+	SecLevel string `toml:"sec_level"`
+	SecName  string `toml:"sec_name"`
+	// Values: "MD5", "SHA", "". Default: ""
+	AuthProtocol string `toml:"auth_protocol"`
+	AuthPassword string `toml:"auth_password"`
+	// Values: "DES", "AES", "". Default: ""
+	PrivProtocol string `toml:"priv_protocol"`
+	PrivPassword string `toml:"priv_password"`
+	EngineID     string `toml:"engine_id"`
+	EngineBoots  uint32 `toml:"engine_boots"`
+	EngineTime   uint32 `toml:"engine_time"`
+}
@@ -15,7 +15,7 @@ The commands & flags are:
 
   --aggregator-filter <filter>   filter the aggregators to enable, separator is :
   --config <file>                configuration file to load
-  --config-directory <directory> directory containing additional *.conf files
+  --config-directory <directory> directory containing additional *.conf, *.json and *.yaml files
   --debug                        turn on debug logging
   --input-filter <filter>        filter the inputs to enable, separator is :
   --input-list                   print available input plugins.
@@ -25,6 +25,13 @@ The commands & flags are:
   --pprof-addr <address>         pprof address to listen on, don't activate pprof if empty
   --processor-filter <filter>    filter the processors to enable, separator is :
   --quiet                        run in quiet mode
+  --replay <file>                replay the metrics recorded in a line protocol
+                                 or JSON file through processors and
+                                 aggregators, writing them to the outputs
+  --replay-speed                 replay at this many times the recorded rate;
+                                 0 replays as fast as possible
+  --replay-stdout                print the replayed metrics to stdout instead
+                                 of writing them to the outputs
   --sample-config                print out full sample configuration
   --section-filter               filter config sections to output, separator is :
                                  Valid values are 'agent', 'global_tags', 'outputs',
@@ -33,6 +40,9 @@ The commands & flags are:
                                  processors, aggregators, and outputs are not run
   --test-wait                    wait up to this many seconds for service
                                  inputs to complete in test mode
+  --test-pipeline                like --test, but also run processors and
+                                 aggregators and print what each output would
+                                 write, without connecting to the outputs
   --usage <plugin>               print usage for a plugin, ie, 'telegraf --usage mysql'
   --version                      display the version and exit
 
@@ -52,6 +62,13 @@ Examples:
   # run a single telegraf collection, outputing metrics to stdout
   telegraf --config telegraf.conf --test
 
+  # run a single collection through the whole pipeline, printing what
+  # each output would write
+  telegraf --config telegraf.conf --test-pipeline
+
+  # replay recorded metrics through processors and aggregators to stdout
+  telegraf --config telegraf.conf --replay metrics.lp --replay-stdout
+
   # run telegraf with all plugins defined in config file
   telegraf --config telegraf.conf
 
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/wlog"
+)
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+var (
+	lineRegex = regexp.MustCompile(`(?s)^(?:([DIWE])! )?(?:\[([^\]]*)\] )?(.*)$`)
+
+	levelNames = map[string]string{
+		"D": "debug",
+		"I": "info",
+		"W": "warn",
+		"E": "error",
+	}
+)
+
+// logEntry is a single log line split into its parts.
+type logEntry struct {
+	Timestamp  string `json:"timestamp"`
+	Level      string `json:"level"`
+	PluginType string `json:"plugin_type,omitempty"`
+	PluginName string `json:"plugin_name,omitempty"`
+	Alias      string `json:"alias,omitempty"`
+	Message    string `json:"message"`
+}
+
+// jsonEncoder writes each log line as a JSON object.
+type jsonEncoder struct {
+	writer io.Writer
+}
+
+func (j *jsonEncoder) Write(b []byte) (n int, err error) {
+	entry := parseLine(b)
+	entry.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	line = append(line, '\n')
+
+	if _, err := j.writer.Write(line); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// parseLine splits a log line of the form "E! [inputs.cpu::alias] message"
+// into its parts.
+func parseLine(b []byte) logEntry {
+	parts := lineRegex.FindSubmatch(bytes.TrimRight(b, "\r\n"))
+
+	entry := logEntry{
+		Level:   "info",
+		Message: string(parts[3]),
+	}
+	if level, ok := levelNames[string(parts[1])]; ok {
+		entry.Level = level
+	}
+
+	source := strings.Trim(string(parts[2]), `"`)
+	if i := strings.Index(source, "::"); i >= 0 {
+		entry.Alias = source[i+2:]
+		source = source[:i]
+	}
+
+	switch pluginType := strings.SplitN(source, ".", 2); pluginType[0] {
+	case "inputs", "outputs", "processors", "aggregators":
+		entry.PluginType = pluginType[0]
+		if len(pluginType) > 1 {
+			entry.PluginName = pluginType[1]
+		}
+	default:
+		entry.PluginType = source
+	}
+
+	return entry
+}
+
+// jsonLog is the json format counterpart of telegrafLog.
+type jsonLog struct {
+	writer         io.Writer
+	encoder        io.Writer
+	internalWriter io.Writer
+}
+
+// Write logs the line, lines without a level are logged at info level as
+// with the text format.
+func (j *jsonLog) Write(b []byte) (n int, err error) {
+	if !prefixRegex.Match(b) {
+		if _, err := j.writer.Write(append([]byte("I! "), b...)); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	return j.writer.Write(b)
+}
+
+func (j *jsonLog) Close() error {
+	return closeWriter(j.internalWriter)
+}
+
+func (j *jsonLog) writeDirect(b []byte) (n int, err error) {
+	return j.encoder.Write(b)
+}
+
+// newJSONWriter returns a logging-wrapped writer in the json format.
+func newJSONWriter(w io.Writer) io.Writer {
+	encoder := &jsonEncoder{writer: w}
+	return &jsonLog{
+		writer:         wlog.NewWriter(encoder),
+		encoder:        encoder,
+		internalWriter: w,
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/rotate"
+	framing "github.com/influxdata/telegraf/internal/syslog"
 	"github.com/influxdata/wlog"
 )
 
@@ -26,8 +27,10 @@ type LogConfig struct {
 	Debug bool
 	//will set the log level to ERROR
 	Quiet bool
-	//stderr, stdout, file or eventlog (Windows only)
+	//stderr, stdout, file, syslog, journald or eventlog (Windows only)
 	LogTarget string
+	// text or json, the format of the file and stderr targets
+	LogFormat string
 	// will direct the logging output to a file. Empty string is
 	// interpreted as stderr. If there is an error opening the file the
 	// logger will fallback to stderr
@@ -38,6 +41,11 @@ type LogConfig struct {
 	RotationMaxSize internal.Size
 	// maximum rotated files to keep (older ones will be deleted)
 	RotationMaxArchives int
+	// address of the syslog server for the syslog target, such as
+	// "udp://localhost:514".  Empty string is the local syslog socket.
+	SyslogAddress string
+	// framing of the messages sent to a syslog server over TCP
+	SyslogFraming framing.Framing
 }
 
 type LoggerCreator interface {
@@ -59,21 +67,33 @@ type telegrafLog struct {
 }
 
 func (t *telegrafLog) Write(b []byte) (n int, err error) {
-	var line []byte
+	return t.writer.Write(formatLine(b))
+}
+
+// formatLine prefixes the line with the current time, lines without a level
+// are logged at info level.
+func formatLine(b []byte) []byte {
 	if !prefixRegex.Match(b) {
-		line = append([]byte(time.Now().UTC().Format(time.RFC3339)+" I! "), b...)
-	} else {
-		line = append([]byte(time.Now().UTC().Format(time.RFC3339)+" "), b...)
+		return append([]byte(time.Now().UTC().Format(time.RFC3339)+" I! "), b...)
 	}
-	return t.writer.Write(line)
+	return append([]byte(time.Now().UTC().Format(time.RFC3339)+" "), b...)
 }
 
 func (t *telegrafLog) Close() error {
+	return closeWriter(t.internalWriter)
+}
+
+func (t *telegrafLog) writeDirect(b []byte) (n int, err error) {
+	return t.internalWriter.Write(formatLine(b))
+}
+
+// closeWriter closes the writer of a log target.
+func closeWriter(w io.Writer) error {
 	var stdErrWriter io.Writer
 	stdErrWriter = os.Stderr
 	// avoid closing stderr
-	if t.internalWriter != stdErrWriter {
-		closer, isCloser := t.internalWriter.(io.Closer)
+	if w != stdErrWriter {
+		closer, isCloser := w.(io.Closer)
 		if !isCloser {
 			return errors.New("the underlying writer cannot be closed")
 		}
@@ -120,6 +140,13 @@ func (t *telegrafLogCreator) CreateLogger(config LogConfig) (io.Writer, error) {
 		writer = defaultWriter
 	}
 
+	switch config.LogFormat {
+	case LogFormatJSON:
+		return newJSONWriter(writer), nil
+	case LogFormatText, "":
+	default:
+		log.Printf("E! Unsupported logformat: %s, using text", config.LogFormat)
+	}
 	return newTelegrafWriter(writer), nil
 }
 
@@ -140,7 +167,10 @@ func newLogWriter(config LogConfig) io.Writer {
 	}
 	var logWriter io.Writer
 	if logCreator, ok := loggerRegistry[config.LogTarget]; ok {
-		logWriter, _ = logCreator.CreateLogger(config)
+		var err error
+		if logWriter, err = logCreator.CreateLogger(config); err != nil {
+			log.Printf("E! Unable to create logtarget %s (%s), using stderr", config.LogTarget, err)
+		}
 	}
 	if logWriter == nil {
 		logWriter, _ = (&telegrafLogCreator{}).CreateLogger(config)
@@ -2,12 +2,14 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/internal"
 	"github.com/stretchr/testify/assert"
@@ -151,3 +153,48 @@ func createBasicLogConfig(filename string) LogConfig {
 		RotationMaxArchives: -1,
 	}
 }
+
+func TestWriteJSONLogToFile(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	assert.NoError(t, err)
+	defer func() { os.Remove(tmpfile.Name()) }()
+
+	config := createBasicLogConfig(tmpfile.Name())
+	config.LogFormat = LogFormatJSON
+	SetupLogging(config)
+	log.Printf("E! [inputs.smnet::eth] Error in plugin: %s", "no such device")
+	log.Printf("D! [agent] TEST") // <- should be ignored
+	log.Printf("TEST")
+
+	f, err := ioutil.ReadFile(tmpfile.Name())
+	assert.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSpace(f), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var entry logEntry
+	require.NoError(t, json.Unmarshal(lines[0], &entry))
+	_, err = time.Parse(time.RFC3339Nano, entry.Timestamp)
+	assert.NoError(t, err)
+	entry.Timestamp = ""
+	assert.Equal(t, logEntry{
+		Level:      "error",
+		PluginType: "inputs",
+		PluginName: "smnet",
+		Alias:      "eth",
+		Message:    "Error in plugin: no such device",
+	}, entry)
+
+	entry = logEntry{}
+	require.NoError(t, json.Unmarshal(lines[1], &entry))
+	assert.Equal(t, "info", entry.Level)
+	assert.Equal(t, "", entry.PluginType)
+	assert.Equal(t, "TEST", entry.Message)
+}
+
+func TestParseLine(t *testing.T) {
+	assert.Equal(t, logEntry{Level: "warn", PluginType: "agent", Message: "[\"outputs.file\"] did not complete"},
+		parseLine([]byte("W! [agent] [\"outputs.file\"] did not complete\n")))
+	assert.Equal(t, logEntry{Level: "debug", PluginType: "processors", PluginName: "rename", Message: "multi\nline"},
+		parseLine([]byte("D! [processors.rename] multi\nline\n")))
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/rotate"
+	"github.com/influxdata/wlog"
+)
+
+var (
+	pluginWritersMu sync.Mutex
+	pluginWriters   = make(map[string]io.Writer)
+)
+
+// ParseLevel returns the log level for one of the level names "debug",
+// "info", "warn" or "error".
+func ParseLevel(level string) (wlog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return wlog.DEBUG, nil
+	case "info":
+		return wlog.INFO, nil
+	case "warn", "warning":
+		return wlog.WARN, nil
+	case "error":
+		return wlog.ERROR, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q", level)
+	}
+}
+
+// timestampWriter adds the timestamp to lines without filtering them by the
+// agent log level.
+type timestampWriter struct {
+	writer io.Writer
+}
+
+func (t *timestampWriter) Write(b []byte) (n int, err error) {
+	return t.writer.Write(formatLine(b))
+}
+
+// PluginFileWriter returns a writer for logging a plugin to its own file in
+// the given log format.  Writers are shared between all plugins logging to the
+// same file and are kept open when the configuration is reloaded.  Lines
+// written are not filtered by the agent log level.
+func PluginFileWriter(
+	filename string,
+	format string,
+	rotationInterval time.Duration,
+	rotationMaxSize int64,
+	rotationMaxArchives int,
+) (io.Writer, error) {
+	pluginWritersMu.Lock()
+	defer pluginWritersMu.Unlock()
+
+	if w, ok := pluginWriters[filename]; ok {
+		return w, nil
+	}
+
+	writer, err := rotate.NewFileWriter(filename, rotationInterval, rotationMaxSize, rotationMaxArchives)
+	if err != nil {
+		return nil, err
+	}
+
+	var w io.Writer = &timestampWriter{writer: writer}
+	if format == LogFormatJSON {
+		w = &jsonEncoder{writer: writer}
+	}
+	pluginWriters[filename] = w
+	return w, nil
+}
+
+// directWriter writes to the agent log output without filtering by the agent
+// log level.
+type directWriter struct{}
+
+func (directWriter) Write(b []byte) (n int, err error) {
+	if t, ok := actualLogger.(interface {
+		writeDirect(b []byte) (int, error)
+	}); ok {
+		return t.writeDirect(b)
+	}
+	// Targets without timestamps, such as the eventlog, are always filtered.
+	return log.Writer().Write(b)
+}
+
+// DirectWriter returns a writer to the agent log output that is not filtered
+// by the agent log level; used by plugins with their own log level.
+func DirectWriter() io.Writer {
+	return directWriter{}
+}
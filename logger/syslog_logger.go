@@ -0,0 +1,317 @@
+// +build !windows
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	framing "github.com/influxdata/telegraf/internal/syslog"
+	"github.com/influxdata/wlog"
+)
+
+const (
+	LogTargetSyslog   = "syslog"
+	LogTargetJournald = "journald"
+
+	// syslogFacility is the daemon facility.
+	syslogFacility = 3
+	syslogAppName  = "telegraf"
+	// syslogSDID is the structured data ID for the plugin parameters, 32473
+	// is the private enterprise number reserved for documentation.
+	syslogSDID = "telegraf@32473"
+
+	journaldSocket = "/run/systemd/journal/socket"
+)
+
+var (
+	syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+	// severities maps the log levels to the syslog severities, which are also
+	// used for the journald priority.
+	severities = map[string]int{
+		"debug": 7,
+		"info":  6,
+		"warn":  4,
+		"error": 3,
+	}
+)
+
+// targetLog filters the lines of a log target by the agent log level.
+type targetLog struct {
+	writer io.Writer
+	target io.WriteCloser
+}
+
+func (t *targetLog) Write(b []byte) (n int, err error) {
+	return t.writer.Write(b)
+}
+
+func (t *targetLog) Close() error {
+	return t.target.Close()
+}
+
+func (t *targetLog) writeDirect(b []byte) (n int, err error) {
+	return t.target.Write(b)
+}
+
+func newTargetLog(target io.WriteCloser) io.Writer {
+	return &targetLog{
+		writer: wlog.NewWriter(target),
+		target: target,
+	}
+}
+
+// syslogWriter sends each log line as a syslog message.  Messages to the
+// local syslog socket use the traditional format understood by all syslog
+// daemons, messages to a remote server use RFC5424.
+type syslogWriter struct {
+	network  string
+	address  string
+	framing  framing.Framing
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (s *syslogWriter) Write(b []byte) (n int, err error) {
+	entry := parseLine(b)
+	if strings.TrimSpace(entry.Message) == "" {
+		return len(b), nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.format(entry, time.Now())
+
+	// Reconnect once if the connection was lost, for example when the
+	// syslog daemon was restarted.
+	for i := 0; i < 2; i++ {
+		if s.conn == nil {
+			if err = s.connect(); err != nil {
+				return 0, err
+			}
+		}
+		if _, err = s.conn.Write(msg); err == nil {
+			return len(b), nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	return 0, err
+}
+
+func (s *syslogWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *syslogWriter) connect() error {
+	if s.address != "" {
+		conn, err := net.Dial(s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		return nil
+	}
+
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range syslogSockets {
+			if conn, err := net.Dial(network, path); err == nil {
+				s.conn = conn
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unable to connect to the local syslog socket")
+}
+
+// format returns the framed syslog message for the log entry.
+func (s *syslogWriter) format(entry logEntry, t time.Time) []byte {
+	pri := syslogFacility*8 + severities[entry.Level]
+	message := strings.TrimRight(entry.Message, "\r\n")
+
+	if s.address == "" {
+		return []byte(fmt.Sprintf("<%d>%s %s[%d]: %s",
+			pri, t.Format(time.Stamp), syslogAppName, os.Getpid(), message))
+	}
+
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d - %s %s",
+		pri, t.UTC().Format(time.RFC3339Nano), s.hostname, syslogAppName,
+		os.Getpid(), structuredData(entry), message)
+
+	switch {
+	case s.network == "udp":
+		return []byte(msg)
+	case s.framing == framing.NonTransparent:
+		return []byte(msg + "\n")
+	default:
+		return []byte(fmt.Sprintf("%d %s", len(msg), msg))
+	}
+}
+
+// structuredData returns the RFC5424 structured data element with the plugin
+// of the log entry.
+func structuredData(entry logEntry) string {
+	params := make([]string, 0, 3)
+	for _, param := range []struct{ name, value string }{
+		{"plugin_type", entry.PluginType},
+		{"plugin_name", entry.PluginName},
+		{"alias", entry.Alias},
+	} {
+		if param.value == "" {
+			continue
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(param.value)
+		params = append(params, fmt.Sprintf(`%s="%s"`, param.name, value))
+	}
+
+	if len(params) == 0 {
+		return "-"
+	}
+	return "[" + syslogSDID + " " + strings.Join(params, " ") + "]"
+}
+
+// newSyslogWriter returns a writer to the syslog server at the address in the
+// form "udp://host:514" or "tcp://host:514".  An empty address uses the local
+// syslog socket.
+func newSyslogWriter(address string, f framing.Framing) (*syslogWriter, error) {
+	s := &syslogWriter{framing: f}
+	if address != "" {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		switch u.Scheme {
+		case "udp", "udp4", "udp6":
+			s.network = "udp"
+		case "tcp", "tcp4", "tcp6":
+			s.network = "tcp"
+		default:
+			return nil, fmt.Errorf("unsupported syslog address scheme %q", u.Scheme)
+		}
+		s.address = u.Host
+		if u.Port() == "" {
+			s.address = net.JoinHostPort(u.Hostname(), "514")
+		}
+	}
+
+	s.hostname, _ = os.Hostname()
+	if s.hostname == "" {
+		s.hostname = "-"
+	}
+	return s, nil
+}
+
+// journaldWriter sends each log line to the journal using the native
+// protocol, adding the plugin as structured fields.
+type journaldWriter struct {
+	socket string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (j *journaldWriter) Write(b []byte) (n int, err error) {
+	entry := parseLine(b)
+	if strings.TrimSpace(entry.Message) == "" {
+		return len(b), nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.conn == nil {
+		conn, err := net.Dial("unixgram", j.socket)
+		if err != nil {
+			return 0, err
+		}
+		j.conn = conn
+	}
+
+	if _, err := j.conn.Write(journalFields(entry)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (j *journaldWriter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.conn == nil {
+		return nil
+	}
+	err := j.conn.Close()
+	j.conn = nil
+	return err
+}
+
+// journalFields returns the log entry in the journal export format.
+func journalFields(entry logEntry) []byte {
+	var buf bytes.Buffer
+	for _, field := range []struct{ name, value string }{
+		{"MESSAGE", strings.TrimRight(entry.Message, "\r\n")},
+		{"PRIORITY", fmt.Sprint(severities[entry.Level])},
+		{"SYSLOG_IDENTIFIER", syslogAppName},
+		{"TELEGRAF_PLUGIN_TYPE", entry.PluginType},
+		{"TELEGRAF_PLUGIN_NAME", entry.PluginName},
+		{"TELEGRAF_ALIAS", entry.Alias},
+	} {
+		if field.value == "" {
+			continue
+		}
+		buf.WriteString(field.name)
+		// Values with newlines are written with their length prefixed.
+		if strings.Contains(field.value, "\n") {
+			buf.WriteByte('\n')
+			binary.Write(&buf, binary.LittleEndian, uint64(len(field.value)))
+		} else {
+			buf.WriteByte('=')
+		}
+		buf.WriteString(field.value)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+type syslogLogCreator struct {
+}
+
+func (s *syslogLogCreator) CreateLogger(config LogConfig) (io.Writer, error) {
+	writer, err := newSyslogWriter(config.SyslogAddress, config.SyslogFraming)
+	if err != nil {
+		return nil, err
+	}
+	return newTargetLog(writer), nil
+}
+
+type journaldLogCreator struct {
+}
+
+func (j *journaldLogCreator) CreateLogger(config LogConfig) (io.Writer, error) {
+	return newTargetLog(&journaldWriter{socket: journaldSocket}), nil
+}
+
+func init() {
+	registerLogger(LogTargetSyslog, &syslogLogCreator{})
+	registerLogger(LogTargetJournald, &journaldLogCreator{})
+}
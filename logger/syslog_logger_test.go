@@ -0,0 +1,133 @@
+// +build !windows
+
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	framing "github.com/influxdata/telegraf/internal/syslog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteLogToSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	config := createBasicLogConfig("")
+	config.LogTarget = LogTargetSyslog
+	config.SyslogAddress = "udp://" + conn.LocalAddr().String()
+	writer := newLogWriter(config)
+	defer writer.(*targetLog).Close()
+
+	log.Printf("E! [inputs.cpu::mycpu] TEST")
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Regexp(t,
+		regexp.MustCompile(`^<27>1 \S+ \S+ telegraf \d+ - \[telegraf@32473 plugin_type="inputs" plugin_name="cpu" alias="mycpu"\] TEST$`),
+		string(buf[:n]))
+}
+
+func TestWriteLogToSyslogTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	config := createBasicLogConfig("")
+	config.LogTarget = LogTargetSyslog
+	config.SyslogAddress = "tcp://" + listener.Addr().String()
+	config.SyslogFraming = framing.NonTransparent
+	writer := newLogWriter(config)
+	defer writer.(*targetLog).Close()
+
+	log.Printf("W! TEST")
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^<28>1 \S+ \S+ telegraf \d+ - - TEST\n$`), line)
+}
+
+func TestSyslogLevelFilter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	config := createBasicLogConfig("")
+	config.LogTarget = LogTargetSyslog
+	config.SyslogAddress = "udp://" + conn.LocalAddr().String()
+	config.Debug = false
+	writer := newLogWriter(config)
+	defer writer.(*targetLog).Close()
+
+	log.Printf("D! [inputs.cpu] filtered")
+	log.Printf("I! [inputs.cpu] TEST")
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(buf[:n]), `plugin_name="cpu"] TEST`))
+}
+
+func TestSyslogFraming(t *testing.T) {
+	entry := logEntry{Level: "info", Message: "TEST"}
+
+	s, err := newSyslogWriter("tcp://localhost", framing.OctetCounting)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:514", s.address)
+
+	msg := string(s.format(entry, time.Now()))
+	assert.Regexp(t, regexp.MustCompile(`^\d+ <30>1 `), msg)
+	parts := strings.SplitN(msg, " ", 2)
+	assert.Equal(t, parts[0], strconv.Itoa(len(parts[1])))
+
+	_, err = newSyslogWriter("http://localhost", framing.OctetCounting)
+	assert.Error(t, err)
+}
+
+func TestWriteLogToJournald(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	socket := filepath.Join(tmpdir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	j := &journaldWriter{socket: socket}
+	defer j.Close()
+
+	_, err = j.Write([]byte("E! [outputs.file] TEST\nsecond line\n"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len("TEST\nsecond line")))
+	expected := "MESSAGE\n" + string(length[:]) + "TEST\nsecond line\n" +
+		"PRIORITY=3\n" +
+		"SYSLOG_IDENTIFIER=telegraf\n" +
+		"TELEGRAF_PLUGIN_TYPE=outputs\n" +
+		"TELEGRAF_PLUGIN_NAME=file\n"
+	assert.Equal(t, expected, string(buf[:n]))
+}
@@ -0,0 +1,45 @@
+package metric
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"hash/fnv"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+)
+
+// IdempotencyID returns an ID identifying the metric by its series, field
+// keys and timestamp.  The ID is stable across writes and restarts, so that
+// receivers can discard the metrics written again when a write is retried.
+func IdempotencyID(m telegraf.Metric) string {
+	h := fnv.New128a()
+	writeSeries(h, m)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeSeries(h hash.Hash, m telegraf.Metric) {
+	h.Write([]byte(m.Name()))
+	h.Write([]byte("\n"))
+	for _, tag := range m.TagList() {
+		h.Write([]byte(tag.Key))
+		h.Write([]byte("\n"))
+		h.Write([]byte(tag.Value))
+		h.Write([]byte("\n"))
+	}
+
+	keys := make([]string, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		keys = append(keys, field.Key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte("\n"))
+	}
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(m.Time().UnixNano()))
+	h.Write(ts[:])
+}
@@ -26,3 +26,24 @@ type AggregatingOutput interface {
 	// Reset signals the the aggregator period is completed.
 	Reset()
 }
+
+// DryRunOutput is implemented by outputs with their own data format, to show
+// what would be written without connecting.
+type DryRunOutput interface {
+	Output
+
+	// DryRun returns the data Write would send for the metrics.
+	DryRun(metrics []Metric) ([]byte, error)
+}
+
+// PartialWriteError is returned by Write when only the first Written metrics
+// of the batch were sent before the error, so that only the remaining metrics
+// are retried.
+type PartialWriteError struct {
+	Written int
+	Err     error
+}
+
+func (e *PartialWriteError) Error() string {
+	return e.Err.Error()
+}
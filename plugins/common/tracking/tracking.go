@@ -0,0 +1,107 @@
+// Package tracking lets service inputs wait until the metrics they received
+// are written by the outputs before acknowledging them to the sender, giving
+// at-least-once delivery when the sender retries unacknowledged data.
+package tracking
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// ErrNotDelivered is returned when metrics were dropped before being written.
+var ErrNotDelivered = errors.New("metrics were not delivered")
+
+// Tracker adds metric groups to a tracking accumulator and waits for their
+// delivery.
+type Tracker struct {
+	acc telegraf.TrackingAccumulator
+	sem chan struct{}
+
+	mu          sync.Mutex
+	undelivered map[telegraf.TrackingID]chan bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTracker returns a Tracker allowing up to maxUndelivered metric groups to
+// wait for delivery at the same time.
+func NewTracker(acc telegraf.Accumulator, maxUndelivered int) *Tracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &Tracker{
+		acc:         acc.WithTracking(maxUndelivered),
+		sem:         make(chan struct{}, maxUndelivered),
+		undelivered: make(map[telegraf.TrackingID]chan bool),
+		cancel:      cancel,
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.receiveDelivered(ctx)
+	}()
+	return t
+}
+
+// Deliver adds the metrics as a group and waits until they are written by all
+// outputs.  It returns ErrNotDelivered if the metrics were dropped, or the
+// context error if the context is done first, in which case the metrics may
+// still be delivered later.
+func (t *Tracker) Deliver(ctx context.Context, metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		for _, m := range metrics {
+			m.Drop()
+		}
+		return ctx.Err()
+	case t.sem <- struct{}{}:
+	}
+
+	ch := make(chan bool, 1)
+	t.mu.Lock()
+	t.undelivered[t.acc.AddTrackingMetricGroup(metrics)] = ch
+	t.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case delivered := <-ch:
+		if !delivered {
+			return ErrNotDelivered
+		}
+		return nil
+	}
+}
+
+// Stop stops waiting for deliveries.
+func (t *Tracker) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
+
+func (t *Tracker) receiveDelivered(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case info := <-t.acc.Delivered():
+			<-t.sem
+
+			t.mu.Lock()
+			ch, ok := t.undelivered[info.ID()]
+			delete(t.undelivered, info.ID())
+			t.mu.Unlock()
+
+			if ok {
+				ch <- info.Delivered()
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package tracking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now()),
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	acc := &testutil.TrackingAccumulator{Success: true}
+	tracker := NewTracker(acc, 10)
+	defer tracker.Stop()
+
+	require.NoError(t, tracker.Deliver(context.Background(), testMetrics()))
+	assert.Equal(t, 1, len(acc.GetTelegrafMetrics()))
+	require.NoError(t, tracker.Deliver(context.Background(), nil))
+}
+
+func TestDeliverRejected(t *testing.T) {
+	acc := &testutil.TrackingAccumulator{Success: false}
+	tracker := NewTracker(acc, 10)
+	defer tracker.Stop()
+
+	assert.Equal(t, ErrNotDelivered, tracker.Deliver(context.Background(), testMetrics()))
+}
+
+func TestDeliverTimeout(t *testing.T) {
+	acc := &testutil.TrackingAccumulator{Pending: true}
+	tracker := NewTracker(acc, 1)
+	defer tracker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, tracker.Deliver(ctx, testMetrics()))
+
+	// The single slot is taken by the undelivered group.
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, tracker.Deliver(ctx, testMetrics()))
+	assert.Equal(t, 1, len(acc.GetTelegrafMetrics()))
+}
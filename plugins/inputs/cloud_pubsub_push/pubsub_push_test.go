@@ -193,6 +193,10 @@ func (tm *testMetricMaker) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 	return metric
 }
 
+func (tm *testMetricMaker) Log() telegraf.Logger {
+	return testutil.Logger{Name: tm.Name()}
+}
+
 type testOutput struct {
 	// if true, mock a write failure
 	failWrite bool
@@ -2,8 +2,10 @@ package http_listener_v2
 
 import (
 	"compress/gzip"
+	"context"
 	"crypto/subtle"
 	"crypto/tls"
+	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -15,10 +17,15 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/common/tracking"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
 
+// defaultMaxUndeliveredRequests is the default number of requests waiting
+// for the delivery of their metrics when wait_for_delivery is set.
+const defaultMaxUndeliveredRequests = 1000
+
 // defaultMaxBodySize is the default maximum request body size, in bytes.
 // if the request body is over this size, we will return an HTTP 413 error.
 // 500 MB
@@ -46,6 +53,10 @@ type HTTPListenerV2 struct {
 	BasicPassword  string            `toml:"basic_password"`
 	tlsint.ServerConfig
 
+	WaitForDelivery        bool              `toml:"wait_for_delivery"`
+	DeliveryTimeout        internal.Duration `toml:"delivery_timeout"`
+	MaxUndeliveredRequests int               `toml:"max_undelivered_requests"`
+
 	TimeFunc
 	Log telegraf.Logger
 
@@ -54,7 +65,8 @@ type HTTPListenerV2 struct {
 	listener net.Listener
 
 	parsers.Parser
-	acc telegraf.Accumulator
+	acc     telegraf.Accumulator
+	tracker *tracking.Tracker
 }
 
 const sampleConfig = `
@@ -93,6 +105,26 @@ const sampleConfig = `
   # basic_username = "foobar"
   # basic_password = "barfoo"
 
+  ## Respond to a request only once its metrics are written by all outputs,
+  ## with a 500 error if they were dropped, so that the sender can retry the
+  ## request.
+  # wait_for_delivery = false
+
+  ## Maximum time to wait for the delivery of the metrics before responding
+  ## with a 503 error, it must be shorter than the write_timeout.  Defaults
+  ## to half of the write_timeout.
+  ##
+  ## Metrics are written up to the flush_interval after they are received,
+  ## unless metric_batch_size metrics are received before.  Set the
+  ## delivery_timeout, and the write_timeout, above the flush_interval of
+  ## all outputs or requests will fail with a 503 error and be written twice
+  ## when retried.
+  # delivery_timeout = "5s"
+
+  ## Maximum number of requests waiting for the delivery of their metrics,
+  ## further requests wait for a slot.
+  # max_undelivered_requests = 1000
+
   ## Data format to consume.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -116,6 +148,22 @@ func (h *HTTPListenerV2) SetParser(parser parsers.Parser) {
 	h.Parser = parser
 }
 
+// DeliveryWait returns the time a request waits for the delivery of its
+// metrics, zero unless wait_for_delivery is set.
+func (h *HTTPListenerV2) DeliveryWait() time.Duration {
+	if !h.WaitForDelivery {
+		return 0
+	}
+	if h.DeliveryTimeout.Duration > 0 {
+		return h.DeliveryTimeout.Duration
+	}
+	writeTimeout := h.WriteTimeout.Duration
+	if writeTimeout < time.Second {
+		writeTimeout = time.Second * 10
+	}
+	return writeTimeout / 2
+}
+
 // Start starts the http listener service.
 func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 	if h.MaxBodySize.Size == 0 {
@@ -130,6 +178,19 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 	}
 
 	h.acc = acc
+	if h.WaitForDelivery {
+		if h.MaxUndeliveredRequests <= 0 {
+			h.MaxUndeliveredRequests = defaultMaxUndeliveredRequests
+		}
+		h.DeliveryTimeout.Duration = h.DeliveryWait()
+		// The response must be written before the write_timeout, which
+		// started when the request was read.
+		if h.DeliveryTimeout.Duration >= h.WriteTimeout.Duration {
+			return fmt.Errorf("delivery_timeout (%s) must be shorter than write_timeout (%s)",
+				h.DeliveryTimeout.Duration, h.WriteTimeout.Duration)
+		}
+		h.tracker = tracking.NewTracker(acc, h.MaxUndeliveredRequests)
+	}
 
 	tlsConf, err := h.ServerConfig.TLSConfig()
 	if err != nil {
@@ -171,6 +232,9 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 func (h *HTTPListenerV2) Stop() {
 	h.listener.Close()
 	h.wg.Wait()
+	if h.tracker != nil {
+		h.tracker.Stop()
+	}
 }
 
 func (h *HTTPListenerV2) ServeHTTP(res http.ResponseWriter, req *http.Request) {
@@ -224,8 +288,18 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	for _, m := range metrics {
-		h.acc.AddMetric(m)
+	if h.tracker != nil {
+		ctx, cancel := context.WithTimeout(req.Context(), h.DeliveryTimeout.Duration)
+		defer cancel()
+		if err := h.tracker.Deliver(ctx, metrics); err != nil {
+			h.Log.Debugf("Metrics not delivered: %v", err)
+			notDelivered(res, err)
+			return
+		}
+	} else {
+		for _, m := range metrics {
+			h.acc.AddMetric(m)
+		}
 	}
 
 	res.WriteHeader(http.StatusNoContent)
@@ -286,6 +360,16 @@ func internalServerError(res http.ResponseWriter) {
 	res.WriteHeader(http.StatusInternalServerError)
 }
 
+func notDelivered(res http.ResponseWriter, err error) {
+	res.Header().Set("Content-Type", "application/json")
+	if err == tracking.ErrNotDelivered {
+		res.WriteHeader(http.StatusInternalServerError)
+	} else {
+		res.WriteHeader(http.StatusServiceUnavailable)
+	}
+	res.Write([]byte(`{"error":"http: metrics not delivered"}`))
+}
+
 func badRequest(res http.ResponseWriter) {
 	res.Header().Set("Content-Type", "application/json")
 	res.WriteHeader(http.StatusBadRequest)
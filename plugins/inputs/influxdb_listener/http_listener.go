@@ -3,6 +3,7 @@ package http_listener
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/subtle"
 	"crypto/tls"
 	"encoding/json"
@@ -16,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/common/tracking"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/selfstat"
@@ -31,6 +33,10 @@ const (
 	// a single InfluxDB point.
 	// 64 KB
 	DEFAULT_MAX_LINE_SIZE = 64 * 1024
+
+	// DEFAULT_MAX_UNDELIVERED_REQUESTS is the default number of requests
+	// waiting for the delivery of their metrics when wait_for_delivery is set.
+	DEFAULT_MAX_UNDELIVERED_REQUESTS = 1000
 )
 
 type TimeFunc func() time.Time
@@ -49,6 +55,10 @@ type HTTPListener struct {
 	BasicPassword string            `toml:"basic_password"`
 	DatabaseTag   string            `toml:"database_tag"`
 
+	WaitForDelivery        bool              `toml:"wait_for_delivery"`
+	DeliveryTimeout        internal.Duration `toml:"delivery_timeout"`
+	MaxUndeliveredRequests int               `toml:"max_undelivered_requests"`
+
 	TimeFunc
 
 	mu sync.Mutex
@@ -59,6 +69,7 @@ type HTTPListener struct {
 	handler *influx.MetricHandler
 	parser  *influx.Parser
 	acc     telegraf.Accumulator
+	tracker *tracking.Tracker
 	pool    *pool
 
 	BytesRecv       selfstat.Stat
@@ -115,6 +126,26 @@ const sampleConfig = `
   ## You probably want to make sure you have TLS configured above for this.
   # basic_username = "foobar"
   # basic_password = "barfoo"
+
+  ## Respond to a write only once its metrics are written by all outputs,
+  ## with a 500 error if they were dropped, so that the client can retry the
+  ## write.
+  # wait_for_delivery = false
+
+  ## Maximum time to wait for the delivery of the metrics before responding
+  ## with a 503 error, it must be shorter than the write_timeout.  Defaults
+  ## to half of the write_timeout.
+  ##
+  ## Metrics are written up to the flush_interval after they are received,
+  ## unless metric_batch_size metrics are received before.  Set the
+  ## delivery_timeout, and the write_timeout, above the flush_interval of
+  ## all outputs or requests will fail with a 503 error and be written twice
+  ## when retried.
+  # delivery_timeout = "5s"
+
+  ## Maximum number of writes waiting for the delivery of their metrics,
+  ## further writes wait for a slot.
+  # max_undelivered_requests = 1000
 `
 
 func (h *HTTPListener) SampleConfig() string {
@@ -130,6 +161,22 @@ func (h *HTTPListener) Gather(_ telegraf.Accumulator) error {
 	return nil
 }
 
+// DeliveryWait returns the time a request waits for the delivery of its
+// metrics, zero unless wait_for_delivery is set.
+func (h *HTTPListener) DeliveryWait() time.Duration {
+	if !h.WaitForDelivery {
+		return 0
+	}
+	if h.DeliveryTimeout.Duration > 0 {
+		return h.DeliveryTimeout.Duration
+	}
+	writeTimeout := h.WriteTimeout.Duration
+	if writeTimeout < time.Second {
+		writeTimeout = time.Second * 10
+	}
+	return writeTimeout / 2
+}
+
 // Start starts the http listener service.
 func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 	h.mu.Lock()
@@ -167,6 +214,19 @@ func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 	}
 
 	h.acc = acc
+	if h.WaitForDelivery {
+		if h.MaxUndeliveredRequests <= 0 {
+			h.MaxUndeliveredRequests = DEFAULT_MAX_UNDELIVERED_REQUESTS
+		}
+		h.DeliveryTimeout.Duration = h.DeliveryWait()
+		// The response must be written before the write_timeout, which
+		// started when the request was read.
+		if h.DeliveryTimeout.Duration >= h.WriteTimeout.Duration {
+			return fmt.Errorf("delivery_timeout (%s) must be shorter than write_timeout (%s)",
+				h.DeliveryTimeout.Duration, h.WriteTimeout.Duration)
+		}
+		h.tracker = tracking.NewTracker(acc, h.MaxUndeliveredRequests)
+	}
 	h.pool = NewPool(200, int(h.MaxLineSize.Size))
 
 	tlsConf, err := h.ServerConfig.TLSConfig()
@@ -215,6 +275,9 @@ func (h *HTTPListener) Stop() {
 
 	h.listener.Close()
 	h.wg.Wait()
+	if h.tracker != nil {
+		h.tracker.Stop()
+	}
 
 	h.Log.Infof("Stopped HTTP listener service on %s", h.ServiceAddress)
 }
@@ -285,6 +348,7 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 
 	var return400 bool
 	var hangingBytes bool
+	var pending []telegraf.Metric
 	buf := h.pool.get()
 	defer h.pool.put(buf)
 	bufStart := 0
@@ -299,6 +363,9 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 		h.BytesRecv.Incr(int64(n))
 
 		if err == io.EOF {
+			if !h.deliver(res, req, pending) {
+				return
+			}
 			if return400 {
 				badRequest(res, "")
 			} else {
@@ -325,11 +392,14 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 
 		if err == io.ErrUnexpectedEOF {
 			// finished reading the request body
-			err = h.parse(buf[:n+bufStart], now, precision, db)
+			pending, err = h.parse(pending, buf[:n+bufStart], now, precision, db)
 			if err != nil {
 				h.Log.Debugf("%s: %s", err.Error(), bufStart+n)
 				return400 = true
 			}
+			if !h.deliver(res, req, pending) {
+				return
+			}
 			if return400 {
 				if err != nil {
 					badRequest(res, err.Error())
@@ -356,7 +426,8 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 			bufStart = 0
 			continue
 		}
-		if err := h.parse(buf[:i+1], now, precision, db); err != nil {
+		pending, err = h.parse(pending, buf[:i+1], now, precision, db)
+		if err != nil {
 			h.Log.Debug(err.Error())
 			return400 = true
 		}
@@ -369,7 +440,9 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (h *HTTPListener) parse(b []byte, t time.Time, precision, db string) error {
+// parse parses the metrics and adds them to the accumulator, or to pending
+// when waiting for their delivery.
+func (h *HTTPListener) parse(pending []telegraf.Metric, b []byte, t time.Time, precision, db string) ([]telegraf.Metric, error) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -377,7 +450,7 @@ func (h *HTTPListener) parse(b []byte, t time.Time, precision, db string) error
 	h.handler.SetTimeFunc(func() time.Time { return t })
 	metrics, err := h.parser.Parse(b)
 	if err != nil {
-		return fmt.Errorf("unable to parse: %s", err.Error())
+		return pending, fmt.Errorf("unable to parse: %s", err.Error())
 	}
 
 	for _, m := range metrics {
@@ -388,10 +461,31 @@ func (h *HTTPListener) parse(b []byte, t time.Time, precision, db string) error
 		if h.DatabaseTag != "" && db != "" {
 			m.AddTag(h.DatabaseTag, db)
 		}
-		h.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+		if h.tracker != nil {
+			pending = append(pending, m)
+		} else {
+			h.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+		}
 	}
 
-	return nil
+	return pending, nil
+}
+
+// deliver waits for the delivery of the pending metrics if wait_for_delivery
+// is set, and responds with an error if they were not delivered.
+func (h *HTTPListener) deliver(res http.ResponseWriter, req *http.Request, pending []telegraf.Metric) bool {
+	if h.tracker == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.DeliveryTimeout.Duration)
+	defer cancel()
+	if err := h.tracker.Deliver(ctx, pending); err != nil {
+		h.Log.Debugf("Metrics not delivered: %v", err)
+		notDelivered(res, err)
+		return false
+	}
+	return true
 }
 
 func tooLarge(res http.ResponseWriter) {
@@ -402,6 +496,18 @@ func tooLarge(res http.ResponseWriter) {
 	res.Write([]byte(`{"error":"http: request body too large"}`))
 }
 
+func notDelivered(res http.ResponseWriter, err error) {
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("X-Influxdb-Version", "1.0")
+	res.Header().Set("X-Influxdb-Error", "http: metrics not delivered")
+	if err == tracking.ErrNotDelivered {
+		res.WriteHeader(http.StatusInternalServerError)
+	} else {
+		res.WriteHeader(http.StatusServiceUnavailable)
+	}
+	res.Write([]byte(`{"error":"http: metrics not delivered"}`))
+}
+
 func badRequest(res http.ResponseWriter, errString string) {
 	res.Header().Set("Content-Type", "application/json")
 	res.Header().Set("X-Influxdb-Version", "1.0")
@@ -15,6 +15,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/wlog"
 	"github.com/soniah/gosnmp"
@@ -560,54 +561,7 @@ type snmpConnection interface {
 }
 
 // gosnmpWrapper wraps a *gosnmp.GoSNMP object so we can use it as a snmpConnection.
-type gosnmpWrapper struct {
-	*gosnmp.GoSNMP
-}
-
-// Host returns the value of GoSNMP.Target.
-func (gsw gosnmpWrapper) Host() string {
-	return gsw.Target
-}
-
-// Walk wraps GoSNMP.Walk() or GoSNMP.BulkWalk(), depending on whether the
-// connection is using SNMPv1 or newer.
-// Also, if any error is encountered, it will just once reconnect and try again.
-func (gsw gosnmpWrapper) Walk(oid string, fn gosnmp.WalkFunc) error {
-	var err error
-	// On error, retry once.
-	// Unfortunately we can't distinguish between an error returned by gosnmp, and one returned by the walk function.
-	for i := 0; i < 2; i++ {
-		if gsw.Version == gosnmp.Version1 {
-			err = gsw.GoSNMP.Walk(oid, fn)
-		} else {
-			err = gsw.GoSNMP.BulkWalk(oid, fn)
-		}
-		if err == nil {
-			return nil
-		}
-		if err := gsw.GoSNMP.Connect(); err != nil {
-			return Errorf(err, "reconnecting")
-		}
-	}
-	return err
-}
-
-// Get wraps GoSNMP.GET().
-// If any error is encountered, it will just once reconnect and try again.
-func (gsw gosnmpWrapper) Get(oids []string) (*gosnmp.SnmpPacket, error) {
-	var err error
-	var pkt *gosnmp.SnmpPacket
-	for i := 0; i < 2; i++ {
-		pkt, err = gsw.GoSNMP.Get(oids)
-		if err == nil {
-			return pkt, nil
-		}
-		if err := gsw.GoSNMP.Connect(); err != nil {
-			return nil, Errorf(err, "reconnecting")
-		}
-	}
-	return nil, err
-}
+type gosnmpWrapper = snmp.GosnmpWrapper
 
 // getConnection creates a snmpConnection (*gosnmp.GoSNMP) object and caches the
 // result using `agentIndex` as the cache key.  This is done to allow multiple
@@ -618,108 +572,11 @@ func (s *Snmp) getConnection(idx int) (snmpConnection, error) {
 		return gs, nil
 	}
 
-	agent := s.Agents[idx]
-
-	gs := gosnmpWrapper{&gosnmp.GoSNMP{}}
-	s.connectionCache[idx] = gs
-
-	if strings.HasPrefix(agent, "tcp://") {
-		agent = strings.TrimPrefix(agent, "tcp://")
-		gs.Transport = "tcp"
-	}
-	host, portStr, err := net.SplitHostPort(agent)
-	if err != nil {
-		if err, ok := err.(*net.AddrError); !ok || err.Err != "missing port in address" {
-			return nil, Errorf(err, "parsing host")
-		}
-		host = agent
-		portStr = "161"
-	}
-	gs.Target = host
-
-	port, err := strconv.ParseUint(portStr, 10, 16)
+	gs, err := snmp.NewWrapper(s.clientConfig(), s.Agents[idx])
 	if err != nil {
-		return nil, Errorf(err, "parsing port")
-	}
-	gs.Port = uint16(port)
-
-	gs.Timeout = s.Timeout.Duration
-
-	gs.Retries = s.Retries
-
-	switch s.Version {
-	case 3:
-		gs.Version = gosnmp.Version3
-	case 2, 0:
-		gs.Version = gosnmp.Version2c
-	case 1:
-		gs.Version = gosnmp.Version1
-	default:
-		return nil, fmt.Errorf("invalid version")
-	}
-
-	if s.Version < 3 {
-		if s.Community == "" {
-			gs.Community = "public"
-		} else {
-			gs.Community = s.Community
-		}
-	}
-
-	gs.MaxRepetitions = s.MaxRepetitions
-
-	if s.Version == 3 {
-		gs.ContextName = s.ContextName
-
-		sp := &gosnmp.UsmSecurityParameters{}
-		gs.SecurityParameters = sp
-		gs.SecurityModel = gosnmp.UserSecurityModel
-
-		switch strings.ToLower(s.SecLevel) {
-		case "noauthnopriv", "":
-			gs.MsgFlags = gosnmp.NoAuthNoPriv
-		case "authnopriv":
-			gs.MsgFlags = gosnmp.AuthNoPriv
-		case "authpriv":
-			gs.MsgFlags = gosnmp.AuthPriv
-		default:
-			return nil, fmt.Errorf("invalid secLevel")
-		}
-
-		sp.UserName = s.SecName
-
-		switch strings.ToLower(s.AuthProtocol) {
-		case "md5":
-			sp.AuthenticationProtocol = gosnmp.MD5
-		case "sha":
-			sp.AuthenticationProtocol = gosnmp.SHA
-		case "":
-			sp.AuthenticationProtocol = gosnmp.NoAuth
-		default:
-			return nil, fmt.Errorf("invalid authProtocol")
-		}
-
-		sp.AuthenticationPassphrase = s.AuthPassword
-
-		switch strings.ToLower(s.PrivProtocol) {
-		case "des":
-			sp.PrivacyProtocol = gosnmp.DES
-		case "aes":
-			sp.PrivacyProtocol = gosnmp.AES
-		case "":
-			sp.PrivacyProtocol = gosnmp.NoPriv
-		default:
-			return nil, fmt.Errorf("invalid privProtocol")
-		}
-
-		sp.PrivacyPassphrase = s.PrivPassword
-
-		sp.AuthoritativeEngineID = s.EngineID
-
-		sp.AuthoritativeEngineBoots = s.EngineBoots
-
-		sp.AuthoritativeEngineTime = s.EngineTime
+		return nil, err
 	}
+	s.connectionCache[idx] = gs
 
 	if err := gs.Connect(); err != nil {
 		return nil, Errorf(err, "setting up connection")
@@ -728,6 +585,27 @@ func (s *Snmp) getConnection(idx int) (snmpConnection, error) {
 	return gs, nil
 }
 
+// clientConfig returns the options of the connections to the agents.
+func (s *Snmp) clientConfig() snmp.ClientConfig {
+	return snmp.ClientConfig{
+		Timeout:        s.Timeout,
+		Retries:        s.Retries,
+		Version:        s.Version,
+		Community:      s.Community,
+		MaxRepetitions: s.MaxRepetitions,
+		ContextName:    s.ContextName,
+		SecLevel:       s.SecLevel,
+		SecName:        s.SecName,
+		AuthProtocol:   s.AuthProtocol,
+		AuthPassword:   s.AuthPassword,
+		PrivProtocol:   s.PrivProtocol,
+		PrivPassword:   s.PrivPassword,
+		EngineID:       s.EngineID,
+		EngineBoots:    s.EngineBoots,
+		EngineTime:     s.EngineTime,
+	}
+}
+
 // fieldConvert converts from any type according to the conv specification
 //  "float"/"float(0)" will convert the value into a float.
 //  "float(X)" will convert the value into a float, and then move the decimal before Xth right-most digit.
@@ -0,0 +1,198 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Paths serving the result of each check.
+const (
+	metricsCheck = "/metrics"
+	bufferCheck  = "/buffer"
+	outputsCheck = "/outputs"
+	inputsCheck  = "/inputs"
+	configCheck  = "/config"
+)
+
+// CheckResult is the state of a check, with the reasons it is failing.
+type CheckResult struct {
+	Healthy bool     `json:"healthy"`
+	Details []string `json:"details"`
+}
+
+func (r *CheckResult) failf(format string, args ...interface{}) {
+	r.Healthy = false
+	r.Details = append(r.Details, fmt.Sprintf(format, args...))
+}
+
+// AgentChecks checks the state of the agent using its internal statistics.
+type AgentChecks struct {
+	BufferFillPercent     float64 `toml:"buffer_fill_percent"`
+	OutputFailedIntervals int     `toml:"output_failed_intervals"`
+	InputFailedIntervals  int     `toml:"input_failed_intervals"`
+	ConfigReload          bool    `toml:"config_reload"`
+
+	outputs map[string]*pluginState
+	inputs  map[string]*pluginState
+}
+
+// pluginState tracks the intervals a plugin has been failing for.
+type pluginState struct {
+	errors    int64
+	processed int64
+	failed    int
+}
+
+// Check evaluates the enabled checks on the internal statistics of the agent
+// as returned by selfstat.  Intervals are counted between calls to Check.
+func (a *AgentChecks) Check(stats []telegraf.Metric) map[string]*CheckResult {
+	results := make(map[string]*CheckResult)
+	if a.BufferFillPercent > 0 {
+		results[bufferCheck] = &CheckResult{Healthy: true}
+	}
+	if a.OutputFailedIntervals > 0 {
+		results[outputsCheck] = &CheckResult{Healthy: true}
+	}
+	if a.InputFailedIntervals > 0 {
+		results[inputsCheck] = &CheckResult{Healthy: true}
+	}
+	if a.ConfigReload {
+		results[configCheck] = &CheckResult{Healthy: true}
+	}
+
+	if a.outputs == nil {
+		a.outputs = make(map[string]*pluginState)
+		a.inputs = make(map[string]*pluginState)
+	}
+
+	for _, ps := range mergeStats(stats) {
+		switch ps.measurement {
+		case "internal_write":
+			if result, ok := results[bufferCheck]; ok {
+				size, _ := fieldInt(ps.fields, "buffer_size")
+				limit, _ := fieldInt(ps.fields, "buffer_limit")
+				if limit > 0 {
+					fill := 100 * float64(size) / float64(limit)
+					if fill > a.BufferFillPercent {
+						result.failf("%s: buffer is %.1f%% full (%d of %d metrics)",
+							ps.name, fill, size, limit)
+					}
+				}
+			}
+
+			if result, ok := results[outputsCheck]; ok {
+				errors, _ := fieldInt(ps.fields, "write_errors")
+				written, _ := fieldInt(ps.fields, "metrics_written")
+				state := a.update(a.outputs, ps.name, errors, written)
+				if state.failed >= a.OutputFailedIntervals {
+					result.failf("%s: no successful write for %d intervals",
+						ps.name, state.failed)
+				}
+			}
+		case "internal_gather":
+			if result, ok := results[inputsCheck]; ok {
+				errors, _ := fieldInt(ps.fields, "errors")
+				gathered, _ := fieldInt(ps.fields, "metrics_gathered")
+				state := a.update(a.inputs, ps.name, errors, gathered)
+				if state.failed >= a.InputFailedIntervals {
+					result.failf("%s: failing for %d intervals", ps.name, state.failed)
+				}
+			}
+		case "internal_agent":
+			if result, ok := results[configCheck]; ok {
+				if failed, ok := fieldInt(ps.fields, "config_reload_failed"); ok && failed > 0 {
+					result.failf("configuration reload failed, running the previous configuration")
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+// update records the counters of the plugin, an interval is failed if errors
+// were logged and no metric was processed since the previous interval.
+func (a *AgentChecks) update(plugins map[string]*pluginState, name string, errors, processed int64) *pluginState {
+	state, ok := plugins[name]
+	if !ok {
+		state = &pluginState{}
+		plugins[name] = state
+	}
+
+	if errors > state.errors && processed == state.processed {
+		state.failed++
+	} else {
+		state.failed = 0
+	}
+	state.errors = errors
+	state.processed = processed
+	return state
+}
+
+// pluginStats are the internal statistics of a plugin.
+type pluginStats struct {
+	measurement string
+	name        string
+	fields      map[string]interface{}
+}
+
+// mergeStats returns the statistics of each plugin sorted by name.  The
+// statistics of a plugin can be split over several metrics: the buffer stats
+// of an output are tagged with an empty alias while its other stats have no
+// alias tag.
+func mergeStats(stats []telegraf.Metric) []*pluginStats {
+	byName := make(map[string]*pluginStats)
+	merged := make([]*pluginStats, 0, len(stats))
+	for _, m := range stats {
+		key := m.Name() + " " + pluginName(m)
+		ps, ok := byName[key]
+		if !ok {
+			ps = &pluginStats{
+				measurement: m.Name(),
+				name:        pluginName(m),
+				fields:      make(map[string]interface{}),
+			}
+			byName[key] = ps
+			merged = append(merged, ps)
+		}
+		for k, v := range m.Fields() {
+			ps.fields[k] = v
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].name < merged[j].name
+	})
+	return merged
+}
+
+// pluginName returns the name of the plugin of an internal metric, as used in
+// the logs.
+func pluginName(m telegraf.Metric) string {
+	var name string
+	switch {
+	case m.HasTag("output"):
+		name, _ = m.GetTag("output")
+		name = "outputs." + name
+	case m.HasTag("input"):
+		name, _ = m.GetTag("input")
+		name = "inputs." + name
+	default:
+		name = m.Name()
+	}
+	if alias, ok := m.GetTag("alias"); ok && alias != "" {
+		name += "::" + alias
+	}
+	return name
+}
+
+func fieldInt(fields map[string]interface{}, key string) (int64, bool) {
+	v, ok := fields[key]
+	if !ok {
+		return 0, false
+	}
+	i, ok := v.(int64)
+	return i, ok
+}
@@ -0,0 +1,191 @@
+package health_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/plugins/outputs/health"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeStats(bufferSize, written, errors int64) telegraf.Metric {
+	return testutil.MustMetric("internal_write",
+		map[string]string{"output": "influxdb", "alias": "primary"},
+		map[string]interface{}{
+			"buffer_size":     bufferSize,
+			"buffer_limit":    int64(100),
+			"metrics_written": written,
+			"write_errors":    errors,
+		},
+		time.Now())
+}
+
+func gatherStats(gathered, errors int64) telegraf.Metric {
+	return testutil.MustMetric("internal_gather",
+		map[string]string{"input": "snmp"},
+		map[string]interface{}{
+			"metrics_gathered": gathered,
+			"errors":           errors,
+		},
+		time.Now())
+}
+
+func TestAgentChecks(t *testing.T) {
+	checks := &health.AgentChecks{
+		BufferFillPercent:     90,
+		OutputFailedIntervals: 2,
+		InputFailedIntervals:  2,
+		ConfigReload:          true,
+	}
+
+	results := checks.Check([]telegraf.Metric{writeStats(10, 10, 0), gatherStats(5, 0)})
+	require.Len(t, results, 4)
+	for path, result := range results {
+		assert.True(t, result.Healthy, path)
+	}
+
+	// Write errors for two intervals without any metric written.
+	checks.Check([]telegraf.Metric{writeStats(95, 10, 1), gatherStats(5, 1)})
+	results = checks.Check([]telegraf.Metric{
+		writeStats(95, 10, 2),
+		gatherStats(5, 2),
+		testutil.MustMetric("internal_agent",
+			map[string]string{},
+			map[string]interface{}{"config_reload_failed": int64(1)},
+			time.Now()),
+	})
+	assert.Equal(t, &health.CheckResult{
+		Details: []string{"outputs.influxdb::primary: buffer is 95.0% full (95 of 100 metrics)"},
+	}, results["/buffer"])
+	assert.Equal(t, &health.CheckResult{
+		Details: []string{"outputs.influxdb::primary: no successful write for 2 intervals"},
+	}, results["/outputs"])
+	assert.Equal(t, &health.CheckResult{
+		Details: []string{"inputs.snmp: failing for 2 intervals"},
+	}, results["/inputs"])
+	assert.False(t, results["/config"].Healthy)
+
+	// A successful write resets the failed intervals.
+	results = checks.Check([]telegraf.Metric{writeStats(0, 105, 2), gatherStats(10, 2)})
+	assert.True(t, results["/buffer"].Healthy)
+	assert.True(t, results["/outputs"].Healthy)
+	assert.True(t, results["/inputs"].Healthy)
+}
+
+// waitStatus waits for the check served at the URL to respond with the
+// status code, and returns its result.
+func waitStatus(t *testing.T, url string, code int) *health.CheckResult {
+	deadline := time.Now().Add(time.Second)
+	for {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		var result health.CheckResult
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode == code {
+			return &result
+		}
+		if time.Now().After(deadline) {
+			require.Equal(t, code, resp.StatusCode, url)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestAgentCheckEndpoints checks that the agent checks are evaluated without
+// any metric written to the output, such as when all inputs are failing.
+func TestAgentCheckEndpoints(t *testing.T) {
+	tags := map[string]string{"output": "health_test"}
+	bufferSize := selfstat.Register("write", "buffer_size", tags)
+	bufferSize.Set(10)
+	selfstat.Register("write", "buffer_limit", tags).Set(100)
+
+	output := health.NewHealth()
+	output.ServiceAddress = "tcp://127.0.0.1:0"
+	output.BufferFillPercent = 90
+	output.CheckInterval = internal.Duration{Duration: 10 * time.Millisecond}
+	require.NoError(t, output.Init())
+	require.NoError(t, output.Connect())
+	defer output.Close()
+
+	waitStatus(t, output.Origin()+"/buffer", http.StatusOK)
+
+	bufferSize.Set(99)
+	result := waitStatus(t, output.Origin()+"/buffer", http.StatusServiceUnavailable)
+	assert.Contains(t, result.Details, "outputs.health_test: buffer is 99.0% full (99 of 100 metrics)")
+
+	resp, err := http.Get(output.Origin())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = http.Get(output.Origin() + "/inputs")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+type flakyOutput struct {
+	fail bool
+}
+
+func (o *flakyOutput) Connect() error       { return nil }
+func (o *flakyOutput) Close() error         { return nil }
+func (o *flakyOutput) Description() string  { return "" }
+func (o *flakyOutput) SampleConfig() string { return "" }
+func (o *flakyOutput) Write(metrics []telegraf.Metric) error {
+	if o.fail {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// TestAgentChecksSelfstat checks the outputs with the statistics registered
+// by the agent, which are split over several metrics.
+func TestAgentChecksSelfstat(t *testing.T) {
+	output := &flakyOutput{}
+	ro := models.NewRunningOutput("health_flaky", output,
+		&models.OutputConfig{Name: "health_flaky"}, 10, 10)
+
+	checks := &health.AgentChecks{
+		BufferFillPercent:     50,
+		OutputFailedIntervals: 1,
+	}
+	checks.Check(selfstat.Snapshot())
+
+	// Each interval a write fails and is retried successfully.
+	for i := 0; i < 3; i++ {
+		ro.AddMetric(testutil.TestMetric(1))
+		output.fail = true
+		require.Error(t, ro.Write())
+		output.fail = false
+		require.NoError(t, ro.Write())
+
+		results := checks.Check(selfstat.Snapshot())
+		for _, detail := range results["/outputs"].Details {
+			assert.NotContains(t, detail, "health_flaky")
+		}
+	}
+
+	// Failing writes without any metric written fail the check, under the
+	// name of the output.
+	for i := 0; i < 6; i++ {
+		ro.AddMetric(testutil.TestMetric(1))
+	}
+	output.fail = true
+	require.Error(t, ro.Write())
+	results := checks.Check(selfstat.Snapshot())
+	assert.Contains(t, results["/outputs"].Details,
+		"outputs.health_flaky: no successful write for 1 intervals")
+	assert.Contains(t, results["/buffer"].Details,
+		"outputs.health_flaky: buffer is 60.0% full (6 of 10 metrics)")
+}
@@ -3,6 +3,7 @@ package health
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"log"
 	"net"
@@ -15,12 +16,14 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 const (
 	defaultServiceAddress = "tcp://:8080"
 	defaultReadTimeout    = 5 * time.Second
 	defaultWriteTimeout   = 5 * time.Second
+	defaultCheckInterval  = 10 * time.Second
 )
 
 var sampleConfig = `
@@ -60,6 +63,23 @@ var sampleConfig = `
   ##
   ## [[outputs.health.contains]]
   ##   field = "buffer_size"
+
+  ## Checks on the internal state of the agent, evaluated every
+  ## check_interval whether or not metrics are written to this output.  Each
+  ## check is served with its details on its own path, the metric checks
+  ## above on /metrics.
+  # check_interval = "10s"
+  ##
+  ## Fail /buffer when an output buffer is fuller than this percentage.
+  # buffer_fill_percent = 90.0
+  ## Fail /outputs when an output failed to write, without writing any metric,
+  ## for this many intervals.
+  # output_failed_intervals = 3
+  ## Fail /inputs when an input had errors, without gathering any metric, for
+  ## this many intervals.
+  # input_failed_intervals = 3
+  ## Fail /config when the last configuration reload failed.
+  # config_reload = false
 `
 
 type Checker interface {
@@ -79,15 +99,21 @@ type Health struct {
 	Contains []*Contains `toml:"contains"`
 	checkers []Checker
 
+	AgentChecks
+	CheckInterval internal.Duration `toml:"check_interval"`
+
 	wg      sync.WaitGroup
+	cancel  context.CancelFunc
 	server  *http.Server
 	origin  string
 	network string
 	address string
 	tlsConf *tls.Config
 
-	mu      sync.Mutex
-	healthy bool
+	mu           sync.Mutex
+	healthy      bool
+	agentResults map[string]*CheckResult
+	metricResult *CheckResult
 }
 
 func (h *Health) SampleConfig() string {
@@ -118,6 +144,10 @@ func (h *Health) Init() error {
 		return errors.New("service_address contains invalid scheme")
 	}
 
+	if h.CheckInterval.Duration <= 0 {
+		return errors.New("check_interval must be positive")
+	}
+
 	h.tlsConf, err = h.ServerConfig.TLSConfig()
 	if err != nil {
 		return err
@@ -155,6 +185,14 @@ func (h *Health) Connect() error {
 
 	log.Printf("I! [outputs.health] Listening on %s", h.origin)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		h.runAgentChecks(ctx)
+	}()
+
 	h.wg.Add(1)
 	go func() {
 		defer h.wg.Done()
@@ -181,35 +219,98 @@ func (h *Health) listen() (net.Listener, error) {
 }
 
 func (h *Health) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Server", internal.ProductToken())
+
+	switch req.URL.Path {
+	case metricsCheck, bufferCheck, outputsCheck, inputsCheck, configCheck:
+		h.serveCheck(rw, req.URL.Path)
+		return
+	}
+
 	var code = http.StatusOK
 	if !h.isHealthy() {
 		code = http.StatusServiceUnavailable
 	}
-
-	rw.Header().Set("Server", internal.ProductToken())
 	http.Error(rw, http.StatusText(code), code)
 }
 
-// Write runs all checks over the metric batch and adjust health state.
+// serveCheck responds with the result of a single check.
+func (h *Health) serveCheck(rw http.ResponseWriter, path string) {
+	result, ok := h.checkResult(path)
+	if !ok {
+		http.Error(rw, "check not enabled", http.StatusNotFound)
+		return
+	}
+
+	code := http.StatusOK
+	if !result.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	json.NewEncoder(rw).Encode(result)
+}
+
+// runAgentChecks evaluates the agent checks every check interval until the
+// context is done.  They do not depend on Write, which is not called when no
+// metrics reach this output.
+func (h *Health) runAgentChecks(ctx context.Context) {
+	h.setAgentResults(h.AgentChecks.Check(selfstat.Snapshot()))
+
+	ticker := time.NewTicker(h.CheckInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.setAgentResults(h.AgentChecks.Check(selfstat.Snapshot()))
+		}
+	}
+}
+
+// Write runs the metric checks over the metric batch and adjust health
+// state.
 func (h *Health) Write(metrics []telegraf.Metric) error {
-	healthy := true
+	if len(h.checkers) == 0 {
+		return nil
+	}
+
+	result := &CheckResult{Healthy: true}
 	for _, checker := range h.checkers {
 		success := checker.Check(metrics)
 		if !success {
-			healthy = false
+			result.failf("%s failed", checkerName(checker))
 		}
 	}
 
-	h.setHealthy(healthy)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.metricResult = result
+	h.updateHealthy()
 	return nil
 }
 
+func checkerName(checker Checker) string {
+	switch c := checker.(type) {
+	case *Compares:
+		return "compares check on field " + c.Field
+	case *Contains:
+		return "contains check on field " + c.Field
+	default:
+		return "metric check"
+	}
+}
+
 // Close shuts down the HTTP server.
 func (h *Health) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	h.server.Shutdown(ctx)
+	if h.cancel != nil {
+		h.cancel()
+	}
 	h.wg.Wait()
 	return nil
 }
@@ -245,10 +346,43 @@ func (h *Health) getOrigin(listener net.Listener) string {
 
 }
 
-func (h *Health) setHealthy(healthy bool) {
+func (h *Health) setAgentResults(results map[string]*CheckResult) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	h.healthy = healthy
+	h.agentResults = results
+	h.updateHealthy()
+}
+
+// updateHealthy sets the overall state from the results, h.mu must be held.
+func (h *Health) updateHealthy() {
+	h.healthy = h.metricResult == nil || h.metricResult.Healthy
+	for _, result := range h.agentResults {
+		if !result.Healthy {
+			h.healthy = false
+		}
+	}
+}
+
+func (h *Health) checkResult(path string) (*CheckResult, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if path == metricsCheck && len(h.checkers) > 0 ||
+		path == bufferCheck && h.BufferFillPercent > 0 ||
+		path == outputsCheck && h.OutputFailedIntervals > 0 ||
+		path == inputsCheck && h.InputFailedIntervals > 0 ||
+		path == configCheck && h.ConfigReload {
+		result, ok := h.agentResults[path]
+		if path == metricsCheck {
+			result, ok = h.metricResult, h.metricResult != nil
+		}
+		if ok {
+			return result, true
+		}
+		// Checks are healthy until evaluated.
+		return &CheckResult{Healthy: true}, true
+	}
+	return nil, false
 }
 
 func (h *Health) isHealthy() bool {
@@ -262,6 +396,7 @@ func NewHealth() *Health {
 		ServiceAddress: defaultServiceAddress,
 		ReadTimeout:    internal.Duration{Duration: defaultReadTimeout},
 		WriteTimeout:   internal.Duration{Duration: defaultWriteTimeout},
+		CheckInterval:  internal.Duration{Duration: defaultCheckInterval},
 		healthy:        true,
 	}
 }
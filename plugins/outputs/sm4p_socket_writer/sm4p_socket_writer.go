@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	tgmetric "github.com/influxdata/telegraf/metric"
 	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"log"
@@ -17,6 +18,7 @@ import (
 type Sm4pSocketWriter struct {
 	Address         string
 	KeepAlivePeriod *internal.Duration
+	IdempotencyKey  string `toml:"idempotency_key"`
 	tlsint.ClientConfig
 
 	//serializers.Serializer
@@ -54,6 +56,10 @@ func (sw *Sm4pSocketWriter) SampleConfig() string {
   ## 0 disables keep alive probes.
   ## Defaults to the OS configuration.
   # keep_alive_period = "5m"
+
+  ## Key of an ID of the metric, stable by series and timestamp, added to the
+  ## objects sent so the receiver can discard metrics sent again on retries.
+  # idempotency_key = "id"
 `
 }
 
@@ -104,7 +110,8 @@ func (sw *Sm4pSocketWriter) setKeepAlive(c net.Conn) error {
 }
 
 // Write writes the given metrics to the destination.
-// If an error is encountered, it is up to the caller to retry the same write again later.
+// If an error is encountered, the metrics not yet sent are reported with a
+// PartialWriteError and retried by the caller on a later write.
 // Not parallel safe.
 func (sw *Sm4pSocketWriter) Write(metrics []telegraf.Metric) error {
 	if sw.Conn == nil {
@@ -114,8 +121,8 @@ func (sw *Sm4pSocketWriter) Write(metrics []telegraf.Metric) error {
 		}
 	}
 
-	for _, m := range metrics {
-		bs, err := serialize(m)
+	for i, m := range metrics {
+		bs, err := sw.serialize(m)
 		if err != nil {
 			log.Printf("D! [outputs.smsocket_writer] Could not serialize metric: %v", err)
 			continue
@@ -123,19 +130,34 @@ func (sw *Sm4pSocketWriter) Write(metrics []telegraf.Metric) error {
 
 		if _, err := sw.Conn.Write(bs); err != nil {
 			//TODO log & keep going with remaining strings
-			if err, ok := err.(net.Error); !ok || !err.Temporary() {
+			if nerr, ok := err.(net.Error); !ok || !nerr.Temporary() {
 				// permanent error. close the connection
 				_ = sw.Close()
 				sw.Conn = nil
-				return fmt.Errorf("closing connection: %v", err)
+				err = fmt.Errorf("closing connection: %v", err)
 			}
-			return err
+			// Only the metrics not yet sent are retried.
+			return &telegraf.PartialWriteError{Written: i, Err: err}
 		}
 	}
 
 	return nil
 }
 
+// DryRun returns the data Write would send for the metrics.
+func (sw *Sm4pSocketWriter) DryRun(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, m := range metrics {
+		bs, err := sw.serialize(m)
+		if err != nil {
+			log.Printf("D! [outputs.smsocket_writer] Could not serialize metric: %v", err)
+			continue
+		}
+		buf = append(buf, bs...)
+	}
+	return buf, nil
+}
+
 // Close closes the connection. Noop if already closed.
 func (sw *Sm4pSocketWriter) Close() error {
 	if sw.Conn == nil {
@@ -150,18 +172,21 @@ func newSm4pSocketWriter() *Sm4pSocketWriter {
 	return &Sm4pSocketWriter{}
 }
 
-func createObject(metric telegraf.Metric) map[string]interface{} {
-	m := make(map[string]interface{}, 4)
+func (sw *Sm4pSocketWriter) createObject(metric telegraf.Metric) map[string]interface{} {
+	m := make(map[string]interface{}, 5)
 	m["ip"] = metric.Tags()["host"]
 	m["type"] = metric.Tags()["type"]
 	m["index"] = metric.Tags()["index"]
 	m["value"] = metric.Fields()["value"]
 	m["timestamp"] = metric.Time().UnixNano() / 1e9
+	if sw.IdempotencyKey != "" {
+		m[sw.IdempotencyKey] = tgmetric.IdempotencyID(metric)
+	}
 	return m
 }
 
-func serialize(metric telegraf.Metric) ([]byte, error) {
-	m := createObject(metric)
+func (sw *Sm4pSocketWriter) serialize(metric telegraf.Metric) ([]byte, error) {
+	m := sw.createObject(metric)
 	serialized, err := json.Marshal(m)
 	if err != nil {
 		return []byte{}, err
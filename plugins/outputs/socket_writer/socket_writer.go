@@ -114,7 +114,8 @@ func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 }
 
 // Write writes the given metrics to the destination.
-// If an error is encountered, it is up to the caller to retry the same write again later.
+// If an error is encountered, the metrics not yet sent are reported with a
+// PartialWriteError and retried by the caller on a later write.
 // Not parallel safe.
 func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 	if sw.Conn == nil {
@@ -124,7 +125,7 @@ func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 		}
 	}
 
-	for _, m := range metrics {
+	for i, m := range metrics {
 		bs, err := sw.Serialize(m)
 		if err != nil {
 			log.Printf("D! [outputs.socket_writer] Could not serialize metric: %v", err)
@@ -132,13 +133,14 @@ func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 		}
 		if _, err := sw.Conn.Write(bs); err != nil {
 			//TODO log & keep going with remaining strings
-			if err, ok := err.(net.Error); !ok || !err.Temporary() {
+			if nerr, ok := err.(net.Error); !ok || !nerr.Temporary() {
 				// permanent error. close the connection
 				sw.Close()
 				sw.Conn = nil
-				return fmt.Errorf("closing connection: %v", err)
+				err = fmt.Errorf("closing connection: %v", err)
 			}
-			return err
+			// Only the metrics not yet sent are retried.
+			return &telegraf.PartialWriteError{Written: i, Err: err}
 		}
 	}
 
@@ -155,6 +155,10 @@ func TestSocketWriter_Write_err(t *testing.T) {
 	err = sw.Write(metrics)
 	require.Error(t, err)
 	assert.Nil(t, sw.Conn)
+
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	assert.Equal(t, 0, perr.Written)
 }
 
 func TestSocketWriter_Write_reconnect(t *testing.T) {
@@ -0,0 +1,243 @@
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Key of the HMAC pseudonyms, the same value is always replaced by the
+  ## same pseudonym with the same key.  It must be at least 32 characters
+  ## long, for example generated with "openssl rand -hex 32".  Use an
+  ## environment variable to keep it out of the configuration file.
+  key = "$ANONYMIZE_KEY"
+
+  ## Rules applied in order to the tags and fields, globs are supported.
+  ## Fields which are not strings are removed, metrics left without fields
+  ## are dropped.
+  [[processors.anonymize.rule]]
+    tags = ["host", "user"]
+    fields = ["cmdline"]
+
+    ## Method to anonymize the values:
+    ##   hmac:     replace by the hex HMAC-SHA256 of the value, truncated to
+    ##             length characters.
+    ##   truncate: keep the first length characters.
+    ##   mask_ip:  keep the prefix of IP addresses, other values are
+    ##             redacted.
+    ##   redact:   replace by the replacement, or remove if empty.
+    method = "hmac"
+
+    ## Length of the pseudonyms, or of the truncated values.
+    # length = 16
+
+    ## Length of the prefixes kept by mask_ip, 0 keeps no part of the
+    ## addresses.
+    # ipv4_prefix = 24
+    # ipv6_prefix = 48
+
+    ## Replacement of the redacted values.
+    # replacement = "REDACTED"
+`
+
+// minKeyLength is the minimum length of the key, shorter keys make the
+// pseudonyms easier to reverse by guessing the key.
+const minKeyLength = 32
+
+// envVarRe matches the environment variables left in the configuration when
+// they are not set.
+var envVarRe = regexp.MustCompile(`\$\{\w+\}|\$\w+`)
+
+type Anonymize struct {
+	Key   string  `toml:"key"`
+	Rules []*Rule `toml:"rule"`
+}
+
+// Rule anonymizes the values of tags and fields with a method.
+type Rule struct {
+	Tags        []string `toml:"tags"`
+	Fields      []string `toml:"fields"`
+	Method      string   `toml:"method"`
+	Length      int      `toml:"length"`
+	IPv4Prefix  *int     `toml:"ipv4_prefix"`
+	IPv6Prefix  *int     `toml:"ipv6_prefix"`
+	Replacement *string  `toml:"replacement"`
+
+	tags   filter.Filter
+	fields filter.Filter
+	key    []byte
+}
+
+func (a *Anonymize) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Anonymize) Description() string {
+	return "Pseudonymize, truncate, mask or redact tag and field values"
+}
+
+func (a *Anonymize) Init() error {
+	for i, r := range a.Rules {
+		if len(r.Tags) == 0 && len(r.Fields) == 0 {
+			return fmt.Errorf("rule %d has no tags or fields", i+1)
+		}
+
+		switch r.Method {
+		case "hmac":
+			if a.Key == "" {
+				return errors.New("key is required by the hmac method")
+			}
+			if v := envVarRe.FindString(a.Key); v != "" {
+				return fmt.Errorf("key contains the unset environment variable %s", v)
+			}
+			if len(a.Key) < minKeyLength {
+				return fmt.Errorf("key must be at least %d characters long", minKeyLength)
+			}
+			if r.Length == 0 {
+				r.Length = 16
+			}
+			if r.Length < 0 || r.Length > 2*sha256.Size {
+				return fmt.Errorf("rule %d: length must be between 1 and %d", i+1, 2*sha256.Size)
+			}
+		case "truncate":
+			if r.Length <= 0 {
+				return fmt.Errorf("rule %d: length is required by the truncate method", i+1)
+			}
+		case "mask_ip":
+			if r.IPv4Prefix == nil {
+				prefix := 24
+				r.IPv4Prefix = &prefix
+			}
+			if r.IPv6Prefix == nil {
+				prefix := 48
+				r.IPv6Prefix = &prefix
+			}
+			if *r.IPv4Prefix < 0 || *r.IPv4Prefix > 32 || *r.IPv6Prefix < 0 || *r.IPv6Prefix > 128 {
+				return fmt.Errorf("rule %d: invalid prefix length", i+1)
+			}
+		case "redact":
+		default:
+			return fmt.Errorf("rule %d: invalid method %q", i+1, r.Method)
+		}
+		if r.Replacement == nil {
+			replacement := "REDACTED"
+			r.Replacement = &replacement
+		}
+
+		var err error
+		if r.tags, err = filter.Compile(r.Tags); err != nil {
+			return err
+		}
+		if r.fields, err = filter.Compile(r.Fields); err != nil {
+			return err
+		}
+		r.key = []byte(a.Key)
+	}
+	return nil
+}
+
+func (a *Anonymize) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := in[:0]
+	for _, m := range in {
+		for _, r := range a.Rules {
+			r.apply(m)
+		}
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func (r *Rule) apply(m telegraf.Metric) {
+	if r.tags != nil {
+		tags := make(map[string]string)
+		for _, tag := range m.TagList() {
+			if r.tags.Match(tag.Key) {
+				tags[tag.Key] = tag.Value
+			}
+		}
+		for k, v := range tags {
+			if v, ok := r.anonymize(v); ok {
+				m.AddTag(k, v)
+			} else {
+				m.RemoveTag(k)
+			}
+		}
+	}
+
+	if r.fields != nil {
+		// Fields which are not strings cannot be anonymized and are removed,
+		// rather than leaving their value.
+		fields := make(map[string]string)
+		var removed []string
+		for _, field := range m.FieldList() {
+			if !r.fields.Match(field.Key) {
+				continue
+			}
+			if s, ok := field.Value.(string); ok {
+				fields[field.Key] = s
+			} else {
+				removed = append(removed, field.Key)
+			}
+		}
+		for k, v := range fields {
+			if v, ok := r.anonymize(v); ok {
+				m.AddField(k, v)
+			} else {
+				m.RemoveField(k)
+			}
+		}
+		for _, k := range removed {
+			m.RemoveField(k)
+		}
+	}
+}
+
+// anonymize returns the anonymized value, false if it is removed.
+func (r *Rule) anonymize(v string) (string, bool) {
+	switch r.Method {
+	case "hmac":
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte(v))
+		return hex.EncodeToString(mac.Sum(nil))[:r.Length], true
+	case "truncate":
+		runes := []rune(v)
+		if len(runes) > r.Length {
+			return string(runes[:r.Length]), true
+		}
+		return v, true
+	case "mask_ip":
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return r.redact()
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4.Mask(net.CIDRMask(*r.IPv4Prefix, 32)).String(), true
+		}
+		return ip.Mask(net.CIDRMask(*r.IPv6Prefix, 128)).String(), true
+	default:
+		return r.redact()
+	}
+}
+
+func (r *Rule) redact() (string, bool) {
+	return *r.Replacement, *r.Replacement != ""
+}
+
+func init() {
+	processors.Add("anonymize", func() telegraf.Processor {
+		return &Anonymize{}
+	})
+}
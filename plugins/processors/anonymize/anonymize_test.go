@@ -0,0 +1,209 @@
+package anonymize
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testKey  = "0123456789abcdef0123456789abcdef"
+	otherKey = "fedcba9876543210fedcba9876543210"
+)
+
+func newMetric(tags map[string]string, fields map[string]interface{}) telegraf.Metric {
+	m, _ := metric.New("proc", tags, fields, time.Unix(0, 0))
+	return m
+}
+
+func TestHMAC(t *testing.T) {
+	a := &Anonymize{
+		Key: testKey,
+		Rules: []*Rule{
+			{Tags: []string{"host", "user"}, Fields: []string{"cmdline"}, Method: "hmac"},
+		},
+	}
+	require.NoError(t, a.Init())
+
+	m1 := newMetric(
+		map[string]string{"host": "db01", "user": "alice", "cpu": "cpu0"},
+		map[string]interface{}{"cmdline": "mysqld --user=alice", "pid": int64(42)},
+	)
+	m2 := newMetric(
+		map[string]string{"host": "db01", "user": "bob"},
+		map[string]interface{}{"cmdline": "mysqld --user=alice"},
+	)
+	a.Apply(m1, m2)
+
+	host, _ := m1.GetTag("host")
+	user, _ := m1.GetTag("user")
+	assert.Len(t, host, 16)
+	assert.NotEqual(t, "db01", host)
+	assert.NotEqual(t, host, user)
+	assert.Equal(t, map[string]string{"host": host, "user": user, "cpu": "cpu0"}, m1.Tags())
+
+	// The same values map to the same pseudonyms.
+	host2, _ := m2.GetTag("host")
+	user2, _ := m2.GetTag("user")
+	assert.Equal(t, host, host2)
+	assert.NotEqual(t, user, user2)
+	assert.Equal(t, m1.Fields()["cmdline"], m2.Fields()["cmdline"])
+	assert.Len(t, m1.Fields()["cmdline"], 16)
+	assert.Equal(t, int64(42), m1.Fields()["pid"])
+
+	// Another key gives other pseudonyms.
+	b := &Anonymize{
+		Key:   otherKey,
+		Rules: []*Rule{{Tags: []string{"host"}, Method: "hmac", Length: 64}},
+	}
+	require.NoError(t, b.Init())
+	m3 := newMetric(map[string]string{"host": "db01"}, map[string]interface{}{"value": 1})
+	b.Apply(m3)
+	host3, _ := m3.GetTag("host")
+	assert.Len(t, host3, 64)
+	assert.NotEqual(t, host, host3[:16])
+}
+
+func TestTruncate(t *testing.T) {
+	a := &Anonymize{
+		Rules: []*Rule{{Tags: []string{"disk*"}, Method: "truncate", Length: 4}},
+	}
+	require.NoError(t, a.Init())
+
+	m := newMetric(
+		map[string]string{"diskSn": "WD-WCC4N1234567", "diskId": "ab"},
+		map[string]interface{}{"value": 1},
+	)
+	a.Apply(m)
+	assert.Equal(t, map[string]string{"diskSn": "WD-W", "diskId": "ab"}, m.Tags())
+}
+
+func TestMaskIP(t *testing.T) {
+	a := &Anonymize{
+		Rules: []*Rule{{Tags: []string{"*_ip"}, Method: "mask_ip"}},
+	}
+	require.NoError(t, a.Init())
+
+	m := newMetric(
+		map[string]string{
+			"src_ip":   "192.168.10.42",
+			"dst_ip":   "2001:db8:1234:5678::1",
+			"other_ip": "not-an-ip",
+		},
+		map[string]interface{}{"value": 1},
+	)
+	a.Apply(m)
+	assert.Equal(t, map[string]string{
+		"src_ip":   "192.168.10.0",
+		"dst_ip":   "2001:db8:1234::",
+		"other_ip": "REDACTED",
+	}, m.Tags())
+
+	// A prefix length of zero keeps no part of the address.
+	zero := 0
+	a = &Anonymize{
+		Rules: []*Rule{{Tags: []string{"*_ip"}, Method: "mask_ip", IPv4Prefix: &zero, IPv6Prefix: &zero}},
+	}
+	require.NoError(t, a.Init())
+
+	m = newMetric(
+		map[string]string{"src_ip": "192.168.10.42", "dst_ip": "2001:db8:1234:5678::1"},
+		map[string]interface{}{"value": 1},
+	)
+	a.Apply(m)
+	assert.Equal(t, map[string]string{"src_ip": "0.0.0.0", "dst_ip": "::"}, m.Tags())
+}
+
+func TestRedact(t *testing.T) {
+	empty := ""
+	a := &Anonymize{
+		Rules: []*Rule{
+			{Tags: []string{"uniqueIdent"}, Method: "redact"},
+			{Fields: []string{"cmdline"}, Method: "redact", Replacement: &empty},
+		},
+	}
+	require.NoError(t, a.Init())
+
+	m := newMetric(
+		map[string]string{"uniqueIdent": "4C4C4544-0043"},
+		map[string]interface{}{"cmdline": "sshd -D", "value": 1},
+	)
+	a.Apply(m)
+	assert.Equal(t, map[string]string{"uniqueIdent": "REDACTED"}, m.Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(1)}, m.Fields())
+}
+
+func TestNonStringFields(t *testing.T) {
+	a := &Anonymize{
+		Key:   testKey,
+		Rules: []*Rule{{Fields: []string{"serial*"}, Method: "hmac"}},
+	}
+	require.NoError(t, a.Init())
+
+	m1 := newMetric(nil, map[string]interface{}{
+		"serial":     "WD-WCC4N1234567",
+		"serial_num": int64(1234567),
+		"value":      1,
+	})
+	m2 := newMetric(nil, map[string]interface{}{"serial_num": int64(7654321)})
+	out := a.Apply(m1, m2)
+
+	// The integer serial is removed instead of being left as is, and the
+	// metric left without fields is dropped.
+	require.Len(t, out, 1)
+	require.Equal(t, []string{"serial", "value"}, fieldKeys(out[0]))
+	require.Len(t, out[0].Fields()["serial"], 16)
+}
+
+func fieldKeys(m telegraf.Metric) []string {
+	var keys []string
+	for _, field := range m.FieldList() {
+		keys = append(keys, field.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestRulesInOrder(t *testing.T) {
+	a := &Anonymize{
+		Key: testKey,
+		Rules: []*Rule{
+			{Tags: []string{"host"}, Method: "hmac"},
+			{Tags: []string{"host"}, Method: "truncate", Length: 8},
+		},
+	}
+	require.NoError(t, a.Init())
+
+	m := newMetric(map[string]string{"host": "db01"}, map[string]interface{}{"value": 1})
+	a.Apply(m)
+	host, _ := m.GetTag("host")
+	assert.Len(t, host, 8)
+}
+
+func TestInitErrors(t *testing.T) {
+	prefix := 33
+	tests := []struct {
+		name string
+		a    *Anonymize
+	}{
+		{"no key", &Anonymize{Rules: []*Rule{{Tags: []string{"host"}, Method: "hmac"}}}},
+		{"unset key", &Anonymize{Key: "$ANONYMIZE_KEY", Rules: []*Rule{{Tags: []string{"host"}, Method: "hmac"}}}},
+		{"unset key in braces", &Anonymize{Key: "${ANONYMIZE_KEY}" + testKey, Rules: []*Rule{{Tags: []string{"host"}, Method: "hmac"}}}},
+		{"short key", &Anonymize{Key: "secret", Rules: []*Rule{{Tags: []string{"host"}, Method: "hmac"}}}},
+		{"no tags", &Anonymize{Key: testKey, Rules: []*Rule{{Method: "hmac"}}}},
+		{"invalid method", &Anonymize{Rules: []*Rule{{Tags: []string{"host"}, Method: "md5"}}}},
+		{"hmac length", &Anonymize{Key: testKey, Rules: []*Rule{{Tags: []string{"host"}, Method: "hmac", Length: 65}}}},
+		{"truncate length", &Anonymize{Rules: []*Rule{{Tags: []string{"host"}, Method: "truncate"}}}},
+		{"prefix", &Anonymize{Rules: []*Rule{{Tags: []string{"ip"}, Method: "mask_ip", IPv4Prefix: &prefix}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.a.Init())
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package dedup
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Fields with the same value as the last one passed for the series are
+  ## dropped, unless it was passed longer than dedup_interval ago.  Metrics
+  ## with no changed fields are dropped.
+  # dedup_interval = "10m"
+`
+
+type Dedup struct {
+	DedupInterval internal.Duration `toml:"dedup_interval"`
+
+	// mu guards the cache, Apply is called for both the gathered metrics
+	// and the aggregations.
+	mu sync.Mutex
+	// cache holds the last value passed of the fields by series.
+	cache map[uint64]map[string]field
+	// purged is the time of the latest metric when the cache was purged.
+	purged time.Time
+}
+
+// field is a value passed and the time of the metric it was passed with.
+// Only the value is kept as the metric is modified by the next processors.
+type field struct {
+	value interface{}
+	time  time.Time
+}
+
+func (d *Dedup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Dedup) Description() string {
+	return "Drop fields and metrics with values unchanged since the last one passed"
+}
+
+func (d *Dedup) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cache == nil {
+		d.cache = make(map[uint64]map[string]field)
+	}
+
+	out := in[:0]
+	for _, m := range in {
+		if d.dedup(m) {
+			out = append(out, m)
+		} else {
+			m.Drop()
+		}
+	}
+	return out
+}
+
+// dedup removes the unchanged fields of the metric, it returns false if no
+// field changed.
+func (d *Dedup) dedup(m telegraf.Metric) bool {
+	now := m.Time()
+	id := m.HashID()
+	fields, ok := d.cache[id]
+	if !ok {
+		fields = make(map[string]field, len(m.FieldList()))
+		d.cache[id] = fields
+	}
+
+	var unchanged []string
+	for _, f := range m.FieldList() {
+		last, ok := fields[f.Key]
+		if ok && last.value == f.Value && now.Sub(last.time) < d.DedupInterval.Duration {
+			unchanged = append(unchanged, f.Key)
+			continue
+		}
+		fields[f.Key] = field{value: f.Value, time: now}
+	}
+
+	if now.Sub(d.purged) >= d.DedupInterval.Duration {
+		d.purge(now)
+	}
+
+	if len(unchanged) == len(m.FieldList()) {
+		return false
+	}
+	for _, key := range unchanged {
+		m.RemoveField(key)
+	}
+	return true
+}
+
+// purge removes the fields that will be passed anyway on their next value,
+// so that the cache does not keep series that are no longer seen.
+func (d *Dedup) purge(now time.Time) {
+	for id, fields := range d.cache {
+		for key, f := range fields {
+			if now.Sub(f.time) >= d.DedupInterval.Duration {
+				delete(fields, key)
+			}
+		}
+		if len(fields) == 0 {
+			delete(d.cache, id)
+		}
+	}
+	d.purged = now
+}
+
+func init() {
+	processors.Add("dedup", func() telegraf.Processor {
+		return &Dedup{
+			DedupInterval: internal.Duration{Duration: 10 * time.Minute},
+		}
+	})
+}
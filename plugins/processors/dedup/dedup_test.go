@@ -0,0 +1,165 @@
+package dedup
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newDedup() *Dedup {
+	return &Dedup{
+		DedupInterval: internal.Duration{Duration: 10 * time.Minute},
+	}
+}
+
+func ifMetric(host string, fields map[string]interface{}, t time.Time) telegraf.Metric {
+	return testutil.MustMetric("smnet",
+		map[string]string{"host": host},
+		fields,
+		t)
+}
+
+func TestDedup(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+
+	tests := []struct {
+		name     string
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "unchanged metric dropped",
+			input: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11", "mtu": int64(1500)}, now),
+				ifMetric("a", map[string]interface{}{"mac": "00:11", "mtu": int64(1500)}, now.Add(time.Minute)),
+			},
+			expected: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11", "mtu": int64(1500)}, now),
+			},
+		},
+		{
+			name: "unchanged fields dropped",
+			input: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11", "mtu": int64(1500)}, now),
+				ifMetric("a", map[string]interface{}{"mac": "00:11", "mtu": int64(9000)}, now.Add(time.Minute)),
+			},
+			expected: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11", "mtu": int64(1500)}, now),
+				ifMetric("a", map[string]interface{}{"mtu": int64(9000)}, now.Add(time.Minute)),
+			},
+		},
+		{
+			name: "new field passed",
+			input: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now),
+				ifMetric("a", map[string]interface{}{"mac": "00:11", "ip": "10.0.0.1"}, now.Add(time.Minute)),
+			},
+			expected: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now),
+				ifMetric("a", map[string]interface{}{"ip": "10.0.0.1"}, now.Add(time.Minute)),
+			},
+		},
+		{
+			name: "series are separate",
+			input: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now),
+				ifMetric("b", map[string]interface{}{"mac": "00:11"}, now),
+			},
+			expected: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now),
+				ifMetric("b", map[string]interface{}{"mac": "00:11"}, now),
+			},
+		},
+		{
+			name: "same value with different type passed",
+			input: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mtu": int64(1500)}, now),
+				ifMetric("a", map[string]interface{}{"mtu": 1500.0}, now.Add(time.Minute)),
+			},
+			expected: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mtu": int64(1500)}, now),
+				ifMetric("a", map[string]interface{}{"mtu": 1500.0}, now.Add(time.Minute)),
+			},
+		},
+		{
+			name: "periodic refresh",
+			input: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now),
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now.Add(5*time.Minute)),
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now.Add(10*time.Minute)),
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now.Add(15*time.Minute)),
+			},
+			expected: []telegraf.Metric{
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now),
+				ifMetric("a", map[string]interface{}{"mac": "00:11"}, now.Add(10*time.Minute)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDedup()
+			var actual []telegraf.Metric
+			// Metrics are applied one at a time as in the agent.
+			for _, m := range tt.input {
+				actual = append(actual, d.Apply(m)...)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestDedupPurge(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	d := newDedup()
+
+	d.Apply(ifMetric("a", map[string]interface{}{"mac": "00:11"}, now))
+	d.Apply(ifMetric("b", map[string]interface{}{"mac": "00:11"}, now.Add(5*time.Minute)))
+	require.Len(t, d.cache, 2)
+
+	// Series a is not seen for longer than dedup_interval.
+	d.Apply(ifMetric("b", map[string]interface{}{"mac": "00:22"}, now.Add(11*time.Minute)))
+	require.Len(t, d.cache, 1)
+}
+
+// Apply is called concurrently for the metrics and the aggregations.
+func TestDedupConcurrent(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	d := newDedup()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				d.Apply(ifMetric(host, map[string]interface{}{"mac": "00:11"}, now.Add(time.Duration(j)*time.Minute)))
+			}
+		}(strconv.Itoa(i))
+	}
+	wg.Wait()
+	require.Len(t, d.cache, 4)
+}
+
+func TestDedupTracking(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	d := newDedup()
+
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) { delivered++ }
+	for i := 0; i < 2; i++ {
+		m := ifMetric("a", map[string]interface{}{"mac": "00:11"}, now.Add(time.Duration(i)*time.Minute))
+		tm, _ := metric.WithTracking(m, notify)
+		d.Apply(tm)
+	}
+
+	// The dropped metric is delivered.
+	require.Equal(t, 1, delivered)
+}
@@ -0,0 +1,295 @@
+package execd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+const sampleConfig = `
+  ## Program to run as the processor, with its arguments.  The metrics are
+  ## written to its stdin and the processed metrics read from its stdout, one
+  ## per line.
+  command = ["/usr/local/bin/processor", "--flag"]
+
+  ## Delay before restarting the program after it exits, doubled on each
+  ## consecutive restart up to max_restart_delay.
+  # restart_delay = "1s"
+  # max_restart_delay = "1m"
+
+  ## Data format of the metrics exchanged with the program.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  # data_format = "influx"
+`
+
+// stopTimeout is how long the program has to exit after its stdin is closed.
+const stopTimeout = 5 * time.Second
+
+type Execd struct {
+	Command         []string          `toml:"command"`
+	RestartDelay    internal.Duration `toml:"restart_delay"`
+	MaxRestartDelay internal.Duration `toml:"max_restart_delay"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	parser     parsers.Parser
+	serializer serializers.Serializer
+	acc        telegraf.Accumulator
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	warned bool
+
+	exited chan error
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (e *Execd) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Execd) Description() string {
+	return "Process metrics with an external program over stdin and stdout"
+}
+
+func (e *Execd) SetParser(parser parsers.Parser) {
+	e.parser = parser
+}
+
+func (e *Execd) SetSerializer(serializer serializers.Serializer) {
+	e.serializer = serializer
+}
+
+func (e *Execd) Init() error {
+	if len(e.Command) == 0 {
+		return errors.New("command is required")
+	}
+	return nil
+}
+
+func (e *Execd) Start(acc telegraf.Accumulator) error {
+	e.acc = acc
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.supervise(ctx)
+	}()
+	return nil
+}
+
+// Stop closes the stdin of the program and waits for it to exit, so that the
+// metrics it is processing are emitted.
+func (e *Execd) Stop() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+// Apply writes the metrics to the program, the processed metrics are added
+// to the accumulator as they are read.  Metrics are passed unchanged if the
+// processor is not started.
+func (e *Execd) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if e.acc == nil {
+		return in
+	}
+
+	for _, m := range in {
+		e.write(m)
+		m.Drop()
+	}
+	return nil
+}
+
+func (e *Execd) write(m telegraf.Metric) {
+	b, err := e.serializer.Serialize(m)
+	if err != nil {
+		e.Log.Errorf("Could not serialize metric: %v", err)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stdin == nil {
+		if !e.warned {
+			e.Log.Errorf("Program is not running, dropping metrics until it is restarted")
+			e.warned = true
+		}
+		return
+	}
+	if _, err := e.stdin.Write(b); err != nil && !e.warned {
+		e.Log.Errorf("Error writing to program, dropping metrics until it is restarted: %v", err)
+		e.warned = true
+	}
+}
+
+// start starts the program and reads its output until it exits, its exit
+// status is then sent on e.exited.
+func (e *Execd) start() error {
+	cmd := exec.Command(e.Command[0], e.Command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting %s: %v", e.Command[0], err)
+	}
+
+	exited := make(chan error, 1)
+	e.mu.Lock()
+	e.cmd = cmd
+	e.stdin = stdin
+	e.exited = exited
+	e.warned = false
+	e.mu.Unlock()
+
+	go func() {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			e.readOutput(stdout)
+		}()
+		go func() {
+			defer wg.Done()
+			e.readErrors(stderr)
+		}()
+		wg.Wait()
+		exited <- cmd.Wait()
+	}()
+	return nil
+}
+
+// supervise restarts the program when it exits, and stops it when the
+// context is done.
+func (e *Execd) supervise(ctx context.Context) {
+	delay := e.RestartDelay.Duration
+	started := time.Now()
+	for {
+		e.mu.Lock()
+		exited := e.exited
+		e.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			e.stop(exited)
+			return
+		case err := <-exited:
+			e.mu.Lock()
+			e.stdin = nil
+			e.mu.Unlock()
+
+			if err != nil {
+				e.Log.Errorf("Program exited: %v", err)
+			} else {
+				e.Log.Errorf("Program exited")
+			}
+		}
+
+		// The delay is reset once the program ran for a while.
+		if time.Since(started) >= e.MaxRestartDelay.Duration {
+			delay = e.RestartDelay.Duration
+		}
+		for {
+			e.Log.Infof("Restarting program in %s", delay)
+			if err := internal.SleepContext(ctx, delay); err != nil {
+				return
+			}
+			delay *= 2
+			if delay > e.MaxRestartDelay.Duration {
+				delay = e.MaxRestartDelay.Duration
+			}
+
+			if err := e.start(); err != nil {
+				e.Log.Errorf("Could not restart program: %v", err)
+				continue
+			}
+			started = time.Now()
+			break
+		}
+	}
+}
+
+// stop closes the stdin of the program and waits for it to exit, killing it
+// after stopTimeout.
+func (e *Execd) stop(exited chan error) {
+	e.mu.Lock()
+	cmd := e.cmd
+	stdin := e.stdin
+	e.stdin = nil
+	e.mu.Unlock()
+	if stdin == nil {
+		return
+	}
+
+	stdin.Close()
+	select {
+	case <-exited:
+	case <-time.After(stopTimeout):
+		e.Log.Errorf("Program did not exit after %s, killing it", stopTimeout)
+		cmd.Process.Kill()
+		<-exited
+	}
+}
+
+func (e *Execd) readOutput(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		metrics, err := e.parser.Parse(scanner.Bytes())
+		if err != nil {
+			e.Log.Errorf("Could not parse output of program: %v", err)
+			continue
+		}
+		for _, m := range metrics {
+			e.acc.AddMetric(m)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		e.Log.Errorf("Error reading output of program: %v", err)
+	}
+}
+
+func (e *Execd) readErrors(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		e.Log.Errorf("%s", scanner.Text())
+	}
+}
+
+func init() {
+	processors.Add("execd", func() telegraf.Processor {
+		return &Execd{
+			RestartDelay:    internal.Duration{Duration: time.Second},
+			MaxRestartDelay: internal.Duration{Duration: time.Minute},
+		}
+	})
+}
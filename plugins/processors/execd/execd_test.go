@@ -0,0 +1,83 @@
+package execd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newExecd(t *testing.T, command ...string) *Execd {
+	parser, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+	serializer, err := serializers.NewInfluxSerializer()
+	require.NoError(t, err)
+
+	e := &Execd{
+		Command:         command,
+		RestartDelay:    internal.Duration{Duration: 10 * time.Millisecond},
+		MaxRestartDelay: internal.Duration{Duration: 100 * time.Millisecond},
+		Log:             testutil.Logger{},
+	}
+	e.SetParser(parser)
+	e.SetSerializer(serializer)
+	require.NoError(t, e.Init())
+	return e
+}
+
+func testMetric(value int64) telegraf.Metric {
+	return testutil.MustMetric("cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value": value},
+		time.Unix(0, 0))
+}
+
+func TestExecdPassthrough(t *testing.T) {
+	e := newExecd(t, "cat")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+
+	require.Empty(t, e.Apply(testMetric(1), testMetric(2)))
+	e.Stop()
+
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{testMetric(1), testMetric(2)},
+		acc.GetTelegrafMetrics())
+}
+
+func TestExecdRestart(t *testing.T) {
+	// The program exits after each metric.
+	e := newExecd(t, "head", "-n", "1")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+
+	e.Apply(testMetric(1))
+	acc.Wait(1)
+
+	// Retry until the program is restarted.
+	for i := int64(2); acc.NMetrics() < 2; i++ {
+		e.Apply(testMetric(i))
+		time.Sleep(10 * time.Millisecond)
+	}
+	e.Stop()
+	require.Equal(t, testMetric(1).Fields(), acc.GetTelegrafMetrics()[0].Fields())
+}
+
+func TestExecdNotStarted(t *testing.T) {
+	e := newExecd(t, "cat")
+	actual := e.Apply(testMetric(1))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{testMetric(1)}, actual)
+}
+
+func TestExecdInvalidCommand(t *testing.T) {
+	e := newExecd(t, "/nonexistent/processor")
+	require.Error(t, e.Start(&testutil.Accumulator{}))
+
+	e = &Execd{}
+	require.Error(t, e.Init())
+}
@@ -0,0 +1,152 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Fields set to the value of an expression of the fields and tags of the
+  ## metric, in order so that an expression can use the fields set before.
+  ## Expressions support the arithmetic operators + - * / %, the comparison
+  ## operators == != < <= > >=, the boolean operators && || ! and the
+  ## functions abs, ceil, floor, log, log10, max, min, pow, round and sqrt.
+  ## Names of fields or tags with other characters than letters, digits and
+  ## underscores are quoted with backticks.
+  [[processors.expression.field]]
+    name = "mem_used_pct"
+    expression = "used / total * 100"
+
+    ## Type of the field, "float", "integer", "boolean" or "string".  By
+    ## default numbers are floats.
+    # type = "float"
+
+  # [[processors.expression.field]]
+  #   name = "usage_busy"
+  #   expression = "round(100 - usage_idle, 2)"
+`
+
+type Expression struct {
+	Fields []*Field `toml:"field"`
+
+	Log telegraf.Logger `toml:"-"`
+}
+
+// Field is a field set to the value of an expression.
+type Field struct {
+	Name       string `toml:"name"`
+	Expression string `toml:"expression"`
+	Type       string `toml:"type"`
+
+	expr node
+}
+
+func (e *Expression) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Expression) Description() string {
+	return "Set fields to the value of expressions of the fields and tags"
+}
+
+func (e *Expression) Init() error {
+	for _, f := range e.Fields {
+		if f.Name == "" {
+			return fmt.Errorf("field with expression %q has no name", f.Expression)
+		}
+		switch f.Type {
+		case "", "float", "integer", "boolean", "string":
+		default:
+			return fmt.Errorf("field %s: invalid type %q", f.Name, f.Type)
+		}
+
+		var err error
+		f.expr, err = parse(f.Expression)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (e *Expression) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		env := func(name string) (interface{}, bool) {
+			if v, ok := m.GetField(name); ok {
+				return v, true
+			}
+			if v, ok := m.GetTag(name); ok {
+				return v, true
+			}
+			return nil, false
+		}
+
+		for _, f := range e.Fields {
+			v, err := f.expr.eval(env)
+			if err == errMissing {
+				continue
+			}
+			if err == nil {
+				v, err = convert(v, f.Type)
+			}
+			if err != nil {
+				e.Log.Errorf("Could not evaluate %s of %s: %v", f.Name, m.Name(), err)
+				continue
+			}
+			m.AddField(f.Name, v)
+		}
+	}
+	return in
+}
+
+// convert returns the value with the type of the field.  Numbers which are
+// not finite are an error as they cannot be written.
+func convert(v interface{}, typ string) (interface{}, error) {
+	if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return nil, fmt.Errorf("result is %v", f)
+	}
+
+	switch typ {
+	case "integer":
+		switch v := v.(type) {
+		case float64:
+			return int64(v), nil
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+	case "float":
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case bool:
+			if v {
+				return 1.0, nil
+			}
+			return 0.0, nil
+		}
+	case "boolean":
+		switch v := v.(type) {
+		case float64:
+			return v != 0, nil
+		case bool:
+			return v, nil
+		}
+	case "string":
+		return fmt.Sprint(v), nil
+	default:
+		return v, nil
+	}
+	return nil, fmt.Errorf("cannot convert %v to %s", v, typ)
+}
+
+func init() {
+	processors.Add("expression", func() telegraf.Processor {
+		return &Expression{}
+	})
+}
@@ -0,0 +1,93 @@
+package expression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+
+	e := &Expression{
+		Fields: []*Field{
+			{Name: "used_pct", Expression: "used / total * 100"},
+			{Name: "free_pct", Expression: "100 - used_pct"},
+			{Name: "bits_sent", Expression: "bytes_sent * 8", Type: "integer"},
+			{Name: "critical", Expression: "used_pct > 90 || host == 'db01'"},
+			{Name: "label", Expression: "host", Type: "string"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, e.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("mem",
+			map[string]string{"host": "web01"},
+			map[string]interface{}{"used": int64(25), "total": int64(100)},
+			now),
+		testutil.MustMetric("net",
+			map[string]string{"host": "db01"},
+			map[string]interface{}{"bytes_sent": int64(1000)},
+			now),
+		// Division by zero is not set.
+		testutil.MustMetric("mem",
+			map[string]string{"host": "web02"},
+			map[string]interface{}{"used": int64(25), "total": int64(0)},
+			now),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("mem",
+			map[string]string{"host": "web01"},
+			map[string]interface{}{"used": int64(25), "total": int64(100),
+				"used_pct": 25.0, "free_pct": 75.0, "critical": false, "label": "web01"},
+			now),
+		testutil.MustMetric("net",
+			map[string]string{"host": "db01"},
+			map[string]interface{}{"bytes_sent": int64(1000), "bits_sent": int64(8000),
+				"label": "db01"},
+			now),
+		testutil.MustMetric("mem",
+			map[string]string{"host": "web02"},
+			map[string]interface{}{"used": int64(25), "total": int64(0), "label": "web02"},
+			now),
+	}
+
+	actual := e.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestOverwrite(t *testing.T) {
+	e := &Expression{
+		Fields: []*Field{
+			{Name: "value", Expression: "value * 2"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, e.Init())
+
+	actual := e.Apply(testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"value": int64(21)},
+		time.Unix(0, 0)))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0)),
+	}, actual)
+}
+
+func TestInitErrors(t *testing.T) {
+	for _, f := range []*Field{
+		{Expression: "1"},
+		{Name: "a", Expression: "1 +"},
+		{Name: "a", Expression: "1", Type: "duration"},
+	} {
+		e := &Expression{Fields: []*Field{f}}
+		require.Error(t, e.Init())
+	}
+}
@@ -0,0 +1,479 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// errMissing is returned when evaluating an expression using a field or tag
+// the metric does not have.
+var errMissing = errors.New("missing operand")
+
+// env returns the value of the fields and tags by name.
+type env func(name string) (interface{}, bool)
+
+// node is a parsed expression, evaluating to a float64, string or bool.
+type node interface {
+	eval(env env) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (n literal) eval(env env) (interface{}, error) {
+	return n.value, nil
+}
+
+type ident struct {
+	name string
+}
+
+func (n ident) eval(env env) (interface{}, error) {
+	v, ok := env(n.name)
+	if !ok {
+		return nil, errMissing
+	}
+	switch v := v.(type) {
+	case float64, string, bool:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	default:
+		return nil, fmt.Errorf("%s has unsupported type %T", n.name, v)
+	}
+}
+
+type unary struct {
+	op string
+	x  node
+}
+
+func (n unary) eval(env env) (interface{}, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "-":
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid operand of -: %v", v)
+		}
+		return -f, nil
+	default:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operand of !: %v", v)
+		}
+		return !b, nil
+	}
+}
+
+type binary struct {
+	op   string
+	x, y node
+}
+
+func (n binary) eval(env env) (interface{}, error) {
+	x, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || only evaluate the right operand if needed.
+	if n.op == "&&" || n.op == "||" {
+		b, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operand of %s: %v", n.op, x)
+		}
+		if b == (n.op == "||") {
+			return b, nil
+		}
+		y, err := n.y.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := y.(bool); !ok {
+			return nil, fmt.Errorf("invalid operand of %s: %v", n.op, y)
+		}
+		return y, nil
+	}
+
+	y, err := n.y.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(x, y)
+	case "!=":
+		eq, err := equal(x, y)
+		if err != nil {
+			return nil, err
+		}
+		return !eq.(bool), nil
+	}
+
+	if xs, ok := x.(string); ok {
+		ys, ok := y.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid operands of %s: %v and %v", n.op, x, y)
+		}
+		switch n.op {
+		case "+":
+			return xs + ys, nil
+		case "<":
+			return xs < ys, nil
+		case "<=":
+			return xs <= ys, nil
+		case ">":
+			return xs > ys, nil
+		case ">=":
+			return xs >= ys, nil
+		}
+		return nil, fmt.Errorf("invalid operands of %s: %v and %v", n.op, x, y)
+	}
+
+	xf, xok := x.(float64)
+	yf, yok := y.(float64)
+	if !xok || !yok {
+		return nil, fmt.Errorf("invalid operands of %s: %v and %v", n.op, x, y)
+	}
+	switch n.op {
+	case "+":
+		return xf + yf, nil
+	case "-":
+		return xf - yf, nil
+	case "*":
+		return xf * yf, nil
+	case "/":
+		return xf / yf, nil
+	case "%":
+		return math.Mod(xf, yf), nil
+	case "<":
+		return xf < yf, nil
+	case "<=":
+		return xf <= yf, nil
+	case ">":
+		return xf > yf, nil
+	default:
+		return xf >= yf, nil
+	}
+}
+
+func equal(x, y interface{}) (interface{}, error) {
+	switch x.(type) {
+	case float64:
+		if _, ok := y.(float64); ok {
+			return x == y, nil
+		}
+	case string:
+		if _, ok := y.(string); ok {
+			return x == y, nil
+		}
+	case bool:
+		if _, ok := y.(bool); ok {
+			return x == y, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot compare %v and %v", x, y)
+}
+
+type call struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n call) eval(env env) (interface{}, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid argument of %s: %v", n.name, v)
+		}
+		args[i] = f
+	}
+	return n.fn.call(args), nil
+}
+
+// function is a numeric function taking from min to max arguments, max is
+// -1 for any number of arguments.
+type function struct {
+	min, max int
+	call     func(args []float64) float64
+}
+
+var functions = map[string]function{
+	"abs":   {1, 1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"ceil":  {1, 1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"floor": {1, 1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"sqrt":  {1, 1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"pow":   {2, 2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"log10": {1, 1, func(a []float64) float64 { return math.Log10(a[0]) }},
+	"log": {1, 2, func(a []float64) float64 {
+		if len(a) == 2 {
+			return math.Log(a[0]) / math.Log(a[1])
+		}
+		return math.Log(a[0])
+	}},
+	"round": {1, 2, func(a []float64) float64 {
+		if len(a) == 2 {
+			p := math.Pow(10, math.Trunc(a[1]))
+			return math.Round(a[0]*p) / p
+		}
+		return math.Round(a[0])
+	}},
+	"min": {1, -1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {1, -1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+}
+
+// precedence of the binary operators, higher binds tighter.
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+// tokenize splits the expression into numbers, quoted strings, identifiers
+// and operators.  Identifiers with other characters are quoted with
+// backticks.
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			j := i
+			for j < len(s) && (isIdentChar(rune(s[j])) || s[j] == '.' ||
+				((s[j] == '+' || s[j] == '-') && (s[j-1] == 'e' || s[j-1] == 'E'))) {
+				j++
+			}
+			f, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at %d", s[i:j], i)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: s[i:j], value: f, pos: i})
+			i = j
+		case c == '\'' || c == '"' || c == '`':
+			j := strings.IndexByte(s[i+1:], s[i])
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated quote at %d", i)
+			}
+			text := s[i+1 : i+1+j]
+			if c == '`' {
+				tokens = append(tokens, token{kind: tokIdent, text: text, pos: i})
+			} else {
+				tokens = append(tokens, token{kind: tokString, text: text, value: text, pos: i})
+			}
+			i += j + 2
+		case isIdentChar(c):
+			j := i
+			for j < len(s) && isIdentChar(rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: s[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range []string{"&&", "||", "==", "!=", "<=", ">=", "+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ","} {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(s)}), nil
+}
+
+func isIdentChar(c rune) bool {
+	return c == '_' || c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c))
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse returns the parsed expression.
+func parse(s string) (node, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.expr(1)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.kind != tokOp || t.text != op {
+		return fmt.Errorf("expected %q at %d", op, t.pos)
+	}
+	return nil
+}
+
+// expr parses the binary operators of at least the precedence.
+func (p *parser) expr(prec int) (node, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		opPrec, ok := precedence[t.text]
+		if t.kind != tokOp || !ok || opPrec < prec {
+			return x, nil
+		}
+		p.next()
+		y, err := p.expr(opPrec + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = binary{op: t.text, x: x, y: y}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	t := p.peek()
+	if t.kind == tokOp && (t.text == "-" || t.text == "!") {
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op: t.text, x: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber, tokString:
+		return literal{value: t.value}, nil
+	case tokIdent:
+		if p.peek().kind == tokOp && p.peek().text == "(" {
+			return p.call(t)
+		}
+		switch t.text {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		}
+		return ident{name: t.text}, nil
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.expr(1)
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	case tokEOF:
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *parser) call(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at %d", name.text, name.pos)
+	}
+	p.next()
+
+	var args []node
+	if t := p.peek(); t.kind != tokOp || t.text != ")" {
+		for {
+			arg, err := p.expr(1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if t := p.peek(); t.kind != tokOp || t.text != "," {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if len(args) < fn.min || (fn.max >= 0 && len(args) > fn.max) {
+		return nil, fmt.Errorf("wrong number of arguments of %s at %d", name.text, name.pos)
+	}
+	return call{name: name.text, fn: fn, args: args}, nil
+}
@@ -0,0 +1,118 @@
+package expression
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	values := map[string]interface{}{
+		"used":       int64(25),
+		"total":      uint64(200),
+		"usage_idle": 12.5,
+		"host":       "db01",
+		"up":         true,
+		"usage-user": 3.0,
+	}
+	env := func(name string) (interface{}, bool) {
+		v, ok := values[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		expr     string
+		expected interface{}
+	}{
+		{"used / total * 100", 12.5},
+		{"100 - usage_idle", 87.5},
+		{"1 + 2 * 3 - 4 / 2", 5.0},
+		{"(1 + 2) * 3", 9.0},
+		{"-used + 5", -20.0},
+		{"7 % 4", 3.0},
+		{"1.5e2", 150.0},
+		{"`usage-user` * 2", 6.0},
+		{"abs(-2)", 2.0},
+		{"min(3, used, 1)", 1.0},
+		{"max(3, used, 1)", 25.0},
+		{"round(2.5)", 3.0},
+		{"round(1.23456, 2)", 1.23},
+		{"floor(1.7) + ceil(1.2)", 3.0},
+		{"log(100, 10)", 2.0},
+		{"log10(1000)", 3.0},
+		{"sqrt(pow(3, 2) + pow(4, 2))", 5.0},
+		{"host + '-' + \"a\"", "db01-a"},
+		{"host == 'db01'", true},
+		{"host != 'db01'", false},
+		{"usage_idle < 20 && up", true},
+		{"usage_idle > 20 || !up", false},
+		{"used >= 25 && total <= 200", true},
+		{"true == !false", true},
+		// The right operand is not evaluated.
+		{"up || missing > 1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			n, err := parse(tt.expr)
+			require.NoError(t, err)
+			v, err := n.eval(env)
+			require.NoError(t, err)
+			if f, ok := tt.expected.(float64); ok {
+				require.InDelta(t, f, v, 1e-9)
+				return
+			}
+			require.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	env := func(name string) (interface{}, bool) {
+		if name == "host" {
+			return "db01", true
+		}
+		return nil, false
+	}
+
+	n, err := parse("missing + 1")
+	require.NoError(t, err)
+	_, err = n.eval(env)
+	require.Equal(t, errMissing, err)
+
+	for _, expr := range []string{"host * 2", "host == 1", "!1", "-host", "1 && true", "abs(host)"} {
+		t.Run(expr, func(t *testing.T) {
+			n, err := parse(expr)
+			require.NoError(t, err)
+			_, err = n.eval(env)
+			require.Error(t, err)
+			require.NotEqual(t, errMissing, err)
+		})
+	}
+
+	n, err = parse("1 / 0")
+	require.NoError(t, err)
+	v, err := n.eval(env)
+	require.NoError(t, err)
+	require.True(t, math.IsInf(v.(float64), 1))
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 2",
+		"unknown(1)",
+		"abs(1, 2)",
+		"min()",
+		"'unterminated",
+		"1 # 2",
+		"1.2.3",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := parse(expr)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package lookup
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Table to look the metrics up in, a CSV file with a header row or a JSON
+  ## array of objects.
+  file = "/etc/telegraf/inventory.csv"
+
+  ## Format of the file, "csv" or "json".  By default it is guessed from the
+  ## extension of the file.
+  # format = ""
+
+  ## Tags of the metrics matched against the columns with the same name.
+  key = ["host"]
+
+  ## Columns added to the matching metrics as tags and as fields.  By
+  ## default all the columns other than the key are added as tags.
+  # tags = ["site", "rack", "owner", "business_unit"]
+  # fields = []
+
+  ## Interval to check the file for changes, it is reloaded when modified.
+  # reload_interval = "10s"
+`
+
+type Lookup struct {
+	File           string            `toml:"file"`
+	Format         string            `toml:"format"`
+	Key            []string          `toml:"key"`
+	Tags           []string          `toml:"tags"`
+	Fields         []string          `toml:"fields"`
+	ReloadInterval internal.Duration `toml:"reload_interval"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	table map[string]*entry
+	// modTime and size identify the version of the file loaded.
+	modTime time.Time
+	size    int64
+	checked time.Time
+}
+
+// entry holds the tags and fields added to the metrics matching a row.
+type entry struct {
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+func (l *Lookup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Lookup) Description() string {
+	return "Add tags and fields to metrics from a CSV or JSON table"
+}
+
+func (l *Lookup) Init() error {
+	if l.File == "" {
+		return errors.New("file is required")
+	}
+	if len(l.Key) == 0 {
+		return errors.New("key is required")
+	}
+	if l.Format == "" {
+		l.Format = strings.TrimPrefix(filepath.Ext(l.File), ".")
+	}
+	switch l.Format {
+	case "csv", "json":
+	default:
+		return fmt.Errorf("invalid format %q, must be csv or json", l.Format)
+	}
+
+	info, err := os.Stat(l.File)
+	if err != nil {
+		return err
+	}
+	l.checked = time.Now()
+	return l.load(info)
+}
+
+func (l *Lookup) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	l.reload()
+
+	values := make([]string, len(l.Key))
+	for _, m := range in {
+		ok := true
+		for i, key := range l.Key {
+			values[i], ok = m.GetTag(key)
+			if !ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		e, ok := l.table[joinKey(values)]
+		if !ok {
+			continue
+		}
+		for k, v := range e.tags {
+			m.AddTag(k, v)
+		}
+		for k, v := range e.fields {
+			m.AddField(k, v)
+		}
+	}
+	return in
+}
+
+// reload loads the file again if it was modified, the previous table is
+// kept if it cannot be loaded.
+func (l *Lookup) reload() {
+	now := time.Now()
+	if now.Sub(l.checked) < l.ReloadInterval.Duration {
+		return
+	}
+	l.checked = now
+
+	info, err := os.Stat(l.File)
+	if err != nil {
+		l.Log.Errorf("Could not reload %s, keeping the previous table: %v", l.File, err)
+		return
+	}
+	if info.ModTime().Equal(l.modTime) && info.Size() == l.size {
+		return
+	}
+	if err := l.load(info); err != nil {
+		l.Log.Errorf("Could not reload %s, keeping the previous table: %v", l.File, err)
+		return
+	}
+	l.Log.Infof("Reloaded %s with %d rows", l.File, len(l.table))
+}
+
+func (l *Lookup) load(info os.FileInfo) error {
+	b, err := ioutil.ReadFile(l.File)
+	if err != nil {
+		return err
+	}
+
+	var rows []map[string]interface{}
+	switch l.Format {
+	case "csv":
+		rows, err = parseCSV(b)
+	case "json":
+		rows, err = parseJSON(b)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", l.File, err)
+	}
+
+	table := make(map[string]*entry, len(rows))
+	for i, row := range rows {
+		key, err := l.rowKey(row)
+		if err != nil {
+			return fmt.Errorf("%s: row %d: %v", l.File, i+1, err)
+		}
+		table[key] = l.newEntry(row)
+	}
+
+	l.table = table
+	l.modTime = info.ModTime()
+	l.size = info.Size()
+	return nil
+}
+
+func (l *Lookup) rowKey(row map[string]interface{}) (string, error) {
+	values := make([]string, len(l.Key))
+	for i, key := range l.Key {
+		v, ok := row[key]
+		if !ok {
+			return "", fmt.Errorf("no value for key %s", key)
+		}
+		values[i] = fmt.Sprint(v)
+	}
+	return joinKey(values), nil
+}
+
+func (l *Lookup) newEntry(row map[string]interface{}) *entry {
+	e := &entry{
+		tags:   make(map[string]string),
+		fields: make(map[string]interface{}),
+	}
+
+	if len(l.Tags) == 0 && len(l.Fields) == 0 {
+		for k, v := range row {
+			if !contains(l.Key, k) {
+				e.tags[k] = fmt.Sprint(v)
+			}
+		}
+		return e
+	}
+
+	for _, k := range l.Tags {
+		if v, ok := row[k]; ok {
+			e.tags[k] = fmt.Sprint(v)
+		}
+	}
+	for _, k := range l.Fields {
+		v, ok := row[k]
+		if !ok {
+			continue
+		}
+		// The values of CSV files are all strings.
+		if s, ok := v.(string); ok && l.Format == "csv" {
+			v = parseNumber(s)
+		}
+		e.fields[k] = v
+	}
+	return e
+}
+
+// parseCSV returns the rows of the CSV, using the header row as the column
+// names.
+func parseCSV(b []byte) ([]map[string]interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no header row")
+	}
+
+	header := records[0]
+	rows := make([]map[string]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if record[i] != "" {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseJSON returns the objects of a JSON array, with integral numbers as
+// integers.
+func parseJSON(b []byte) ([]map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var rows []map[string]interface{}
+	if err := dec.Decode(&rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		for k, v := range row {
+			switch v := v.(type) {
+			case json.Number:
+				row[k] = parseNumber(v.String())
+			case string, bool:
+			case nil:
+				delete(row, k)
+			default:
+				return nil, fmt.Errorf("value of %s is not a string, number or boolean", k)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// parseNumber returns the integer or float value of the string, or the
+// string if it is not a number.
+func parseNumber(s string) interface{} {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v
+	}
+	return s
+}
+
+func joinKey(values []string) string {
+	return strings.Join(values, "\x00")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	processors.Add("lookup", func() telegraf.Processor {
+		return &Lookup{
+			ReloadInterval: internal.Duration{Duration: 10 * time.Second},
+		}
+	})
+}
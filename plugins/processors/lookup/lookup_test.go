@@ -0,0 +1,172 @@
+package lookup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const inventoryCSV = `# inventory
+host,ip,site,rack,capacity
+a,10.0.0.1,paris,r1,42
+b,10.0.0.2,london,r7,1.5
+`
+
+const inventoryJSON = `[
+  {"host": "a", "ip": "10.0.0.1", "site": "paris", "rack": "r1", "capacity": 42},
+  {"host": "b", "ip": "10.0.0.2", "site": "london", "rack": "r7", "capacity": 1.5, "owner": null}
+]`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0640))
+	return path
+}
+
+func hostMetric(tags map[string]string, fields map[string]interface{}) telegraf.Metric {
+	return testutil.MustMetric("cpu", tags, fields, time.Unix(0, 0))
+}
+
+func TestLookup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	csvFile := writeFile(t, dir, "inventory.csv", inventoryCSV)
+	jsonFile := writeFile(t, dir, "inventory.json", inventoryJSON)
+
+	tests := []struct {
+		name     string
+		lookup   *Lookup
+		input    telegraf.Metric
+		expected telegraf.Metric
+	}{
+		{
+			name:   "all columns as tags",
+			lookup: &Lookup{File: csvFile, Key: []string{"host"}},
+			input:  hostMetric(map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}),
+			expected: hostMetric(
+				map[string]string{"host": "a", "ip": "10.0.0.1", "site": "paris", "rack": "r1", "capacity": "42"},
+				map[string]interface{}{"value": 1.0}),
+		},
+		{
+			name: "tags and fields",
+			lookup: &Lookup{File: csvFile, Key: []string{"host"},
+				Tags: []string{"site"}, Fields: []string{"capacity"}},
+			input: hostMetric(map[string]string{"host": "b"}, map[string]interface{}{"value": 1.0}),
+			expected: hostMetric(
+				map[string]string{"host": "b", "site": "london"},
+				map[string]interface{}{"value": 1.0, "capacity": 1.5}),
+		},
+		{
+			name: "several keys",
+			lookup: &Lookup{File: csvFile, Key: []string{"host", "ip"},
+				Tags: []string{"rack"}},
+			input: hostMetric(map[string]string{"host": "a", "ip": "10.0.0.1"}, map[string]interface{}{"value": 1.0}),
+			expected: hostMetric(
+				map[string]string{"host": "a", "ip": "10.0.0.1", "rack": "r1"},
+				map[string]interface{}{"value": 1.0}),
+		},
+		{
+			name: "no match on several keys",
+			lookup: &Lookup{File: csvFile, Key: []string{"host", "ip"},
+				Tags: []string{"rack"}},
+			input:    hostMetric(map[string]string{"host": "a", "ip": "10.0.0.2"}, map[string]interface{}{"value": 1.0}),
+			expected: hostMetric(map[string]string{"host": "a", "ip": "10.0.0.2"}, map[string]interface{}{"value": 1.0}),
+		},
+		{
+			name:     "missing key tag",
+			lookup:   &Lookup{File: csvFile, Key: []string{"host"}},
+			input:    hostMetric(map[string]string{}, map[string]interface{}{"value": 1.0}),
+			expected: hostMetric(map[string]string{}, map[string]interface{}{"value": 1.0}),
+		},
+		{
+			name: "json",
+			lookup: &Lookup{File: jsonFile, Key: []string{"ip"},
+				Tags: []string{"site", "owner"}, Fields: []string{"capacity"}},
+			input: hostMetric(map[string]string{"ip": "10.0.0.1"}, map[string]interface{}{"value": 1.0}),
+			expected: hostMetric(
+				map[string]string{"ip": "10.0.0.1", "site": "paris"},
+				map[string]interface{}{"value": 1.0, "capacity": int64(42)}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.lookup.Log = testutil.Logger{}
+			require.NoError(t, tt.lookup.Init())
+			actual := tt.lookup.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestLookupReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := writeFile(t, dir, "inventory.csv", inventoryCSV)
+
+	l := &Lookup{File: file, Key: []string{"host"}, Tags: []string{"site"}, Log: testutil.Logger{}}
+	require.NoError(t, l.Init())
+
+	apply := func() string {
+		m := l.Apply(hostMetric(map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}))[0]
+		site, _ := m.GetTag("site")
+		return site
+	}
+	require.Equal(t, "paris", apply())
+
+	writeFile(t, dir, "inventory.csv", "host,site\na,berlin\n")
+	require.Equal(t, "berlin", apply())
+
+	// The previous table is kept if the file is invalid.
+	writeFile(t, dir, "inventory.csv", "host,site\na,rome,extra\n")
+	require.Equal(t, "berlin", apply())
+}
+
+func TestInitErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name   string
+		lookup *Lookup
+	}{
+		{
+			name:   "no file",
+			lookup: &Lookup{Key: []string{"host"}},
+		},
+		{
+			name:   "no key",
+			lookup: &Lookup{File: writeFile(t, dir, "a.csv", inventoryCSV)},
+		},
+		{
+			name:   "unknown format",
+			lookup: &Lookup{File: writeFile(t, dir, "a.txt", inventoryCSV), Key: []string{"host"}},
+		},
+		{
+			name:   "missing file",
+			lookup: &Lookup{File: filepath.Join(dir, "missing.csv"), Key: []string{"host"}},
+		},
+		{
+			name:   "row without key",
+			lookup: &Lookup{File: writeFile(t, dir, "b.csv", "host,site\n,paris\n"), Key: []string{"host"}},
+		},
+		{
+			name:   "invalid json",
+			lookup: &Lookup{File: writeFile(t, dir, "c.json", `{"host": "a"}`), Key: []string{"host"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.lookup.Init())
+		})
+	}
+}
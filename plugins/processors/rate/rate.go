@@ -0,0 +1,253 @@
+package rate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Counter fields to compute the per second rate of, globs are supported.
+  ## By default the rate of all numeric fields is computed.
+  # fields = ["bytes_*", "packets_*"]
+
+  ## Suffix of the rate fields added next to the counters.
+  # suffix = "_rate"
+
+  ## Replace the value of the counters by their rate instead of adding a
+  ## field with the suffix.  Counters without a rate yet, on their first
+  ## sample or after a reset, are removed.
+  # replace = false
+
+  ## Size in bits of the counters, 32 or 64, to compute the increase of a
+  ## counter wrapping around.  By default a decreasing counter is assumed to
+  ## have been reset.
+  # counter_bits = 0
+
+  ## Maximum time between two samples of a counter, the previous sample is
+  ## discarded if it is older.
+  # max_gap = "10m"
+`
+
+type Rate struct {
+	Fields      []string          `toml:"fields"`
+	Suffix      string            `toml:"suffix"`
+	Replace     bool              `toml:"replace"`
+	CounterBits int               `toml:"counter_bits"`
+	MaxGap      internal.Duration `toml:"max_gap"`
+
+	fields filter.Filter
+	// mu guards the cache, Apply is called for both the gathered metrics
+	// and the aggregations.
+	mu sync.Mutex
+	// cache holds the previous sample of the counters by series.
+	cache map[uint64]map[string]sample
+	// purged is the time of the latest metric when the cache was purged.
+	purged time.Time
+}
+
+// sample is a value of a counter and the time of its metric.
+type sample struct {
+	value interface{}
+	time  time.Time
+}
+
+func (r *Rate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rate) Description() string {
+	return "Compute the per second rate of counters"
+}
+
+func (r *Rate) Init() error {
+	switch r.CounterBits {
+	case 0, 32, 64:
+	default:
+		return fmt.Errorf("invalid counter_bits %d, must be 32 or 64", r.CounterBits)
+	}
+	if !r.Replace && r.Suffix == "" {
+		return errors.New("suffix is required unless replace is set")
+	}
+
+	var err error
+	r.fields, err = filter.Compile(r.Fields)
+	if err != nil {
+		return err
+	}
+	r.cache = make(map[uint64]map[string]sample)
+	return nil
+}
+
+func (r *Rate) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := in[:0]
+	for _, m := range in {
+		r.apply(m)
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+
+	if len(in) > 0 {
+		now := in[len(in)-1].Time()
+		if now.Sub(r.purged) >= r.MaxGap.Duration {
+			r.purge(now)
+		}
+	}
+	return out
+}
+
+func (r *Rate) apply(m telegraf.Metric) {
+	now := m.Time()
+	id := m.HashID()
+	samples, ok := r.cache[id]
+	if !ok {
+		samples = make(map[string]sample)
+		r.cache[id] = samples
+	}
+
+	rates := make(map[string]float64)
+	var removed []string
+	for _, f := range m.FieldList() {
+		if r.fields != nil && !r.fields.Match(f.Key) {
+			continue
+		}
+		if _, ok := toFloat(f.Value); !ok {
+			continue
+		}
+
+		prev, ok := samples[f.Key]
+		samples[f.Key] = sample{value: f.Value, time: now}
+		if ok {
+			if rate, ok := r.rate(prev, f.Value, now); ok {
+				rates[f.Key] = rate
+				continue
+			}
+		}
+		if r.Replace {
+			removed = append(removed, f.Key)
+		}
+	}
+
+	for _, key := range removed {
+		m.RemoveField(key)
+	}
+	for key, rate := range rates {
+		if !r.Replace {
+			key += r.Suffix
+		}
+		m.AddField(key, rate)
+	}
+}
+
+// rate returns the per second rate of the counter between the samples, it
+// returns false if the previous sample is too old or the counter was reset.
+func (r *Rate) rate(prev sample, value interface{}, now time.Time) (float64, bool) {
+	elapsed := now.Sub(prev.time)
+	if elapsed <= 0 || elapsed > r.MaxGap.Duration {
+		return 0, false
+	}
+
+	delta, ok := r.delta(prev.value, value)
+	if !ok {
+		return 0, false
+	}
+	return delta / elapsed.Seconds(), true
+}
+
+// delta returns the increase of the counter.  A decrease is a reset, unless
+// the size of the counters is set and the previous value was in the upper
+// half of their range, in which case it is a wraparound.
+func (r *Rate) delta(prev, value interface{}) (float64, bool) {
+	p, pok := toUint(prev)
+	v, vok := toUint(value)
+	if !pok || !vok {
+		pf, _ := toFloat(prev)
+		vf, _ := toFloat(value)
+		if vf < pf {
+			return 0, false
+		}
+		return vf - pf, true
+	}
+
+	if v >= p {
+		return float64(v - p), true
+	}
+
+	if r.CounterBits == 0 {
+		return 0, false
+	}
+	max := uint64(math.MaxUint64)
+	if r.CounterBits == 32 {
+		max = math.MaxUint32
+	}
+	if p > max || v > max || p < max/2 {
+		return 0, false
+	}
+	return float64(max - p + v + 1), true
+}
+
+// purge removes the samples older than max_gap, so that the cache does not
+// keep series that are no longer seen.
+func (r *Rate) purge(now time.Time) {
+	for id, samples := range r.cache {
+		for key, s := range samples {
+			if now.Sub(s.time) > r.MaxGap.Duration {
+				delete(samples, key)
+			}
+		}
+		if len(samples) == 0 {
+			delete(r.cache, id)
+		}
+	}
+	r.purged = now
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// toUint returns the value of integer counters, which wrap around.
+func toUint(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case int64:
+		if v < 0 {
+			return 0, false
+		}
+		return uint64(v), true
+	case uint64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	processors.Add("rate", func() telegraf.Processor {
+		return &Rate{
+			Suffix: "_rate",
+			MaxGap: internal.Duration{Duration: 10 * time.Minute},
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package rate
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func netMetric(fields map[string]interface{}, t time.Time) telegraf.Metric {
+	return testutil.MustMetric("net",
+		map[string]string{"interface": "eth0"},
+		fields,
+		t)
+}
+
+func TestRate(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+
+	tests := []struct {
+		name     string
+		rate     *Rate
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "rate field added",
+			rate: &Rate{},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(1000)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(3000)}, now.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(1000)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(3000), "bytes_sent_rate": 200.0}, now.Add(10*time.Second)),
+			},
+		},
+		{
+			name: "replace",
+			rate: &Rate{Replace: true},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": int64(1000), "speed": "1G"}, now),
+				netMetric(map[string]interface{}{"bytes_sent": int64(3000), "speed": "1G"}, now.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"speed": "1G"}, now),
+				netMetric(map[string]interface{}{"bytes_sent": 200.0, "speed": "1G"}, now.Add(10*time.Second)),
+			},
+		},
+		{
+			name: "replace drops metrics without rate",
+			rate: &Rate{Replace: true},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": int64(1000)}, now),
+			},
+			expected: []telegraf.Metric{},
+		},
+		{
+			name: "fields filter",
+			rate: &Rate{Fields: []string{"bytes_*"}},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": 10.0, "mtu": int64(1500)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": 15.0, "mtu": int64(1500)}, now.Add(time.Second)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": 10.0, "mtu": int64(1500)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": 15.0, "bytes_sent_rate": 5.0, "mtu": int64(1500)}, now.Add(time.Second)),
+			},
+		},
+		{
+			name: "32 bit wraparound",
+			rate: &Rate{CounterBits: 32},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(math.MaxUint32 - 99)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(100)}, now.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(math.MaxUint32 - 99)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(100), "bytes_sent_rate": 20.0}, now.Add(10*time.Second)),
+			},
+		},
+		{
+			name: "64 bit wraparound",
+			rate: &Rate{CounterBits: 64},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(math.MaxUint64 - 99)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(100)}, now.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(math.MaxUint64 - 99)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(100), "bytes_sent_rate": 20.0}, now.Add(10*time.Second)),
+			},
+		},
+		{
+			name: "counter reset",
+			rate: &Rate{},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(5000)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(100)}, now.Add(10*time.Second)),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(600)}, now.Add(20*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(5000)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(100)}, now.Add(10*time.Second)),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(600), "bytes_sent_rate": 50.0}, now.Add(20*time.Second)),
+			},
+		},
+		{
+			name: "decrease is a reset by default",
+			rate: &Rate{},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(math.MaxUint32 - 99)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(100)}, now.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(math.MaxUint32 - 99)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(100)}, now.Add(10*time.Second)),
+			},
+		},
+		{
+			name: "float counter reset",
+			rate: &Rate{},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"cpu_time_user": 50.5}, now),
+				netMetric(map[string]interface{}{"cpu_time_user": 1.5}, now.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"cpu_time_user": 50.5}, now),
+				netMetric(map[string]interface{}{"cpu_time_user": 1.5}, now.Add(10*time.Second)),
+			},
+		},
+		{
+			name: "max gap",
+			rate: &Rate{},
+			input: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(1000)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(2000)}, now.Add(11*time.Minute)),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(2600)}, now.Add(12*time.Minute)),
+			},
+			expected: []telegraf.Metric{
+				netMetric(map[string]interface{}{"bytes_sent": uint64(1000)}, now),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(2000)}, now.Add(11*time.Minute)),
+				netMetric(map[string]interface{}{"bytes_sent": uint64(2600), "bytes_sent_rate": 10.0}, now.Add(12*time.Minute)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.rate.Suffix == "" {
+				tt.rate.Suffix = "_rate"
+			}
+			tt.rate.MaxGap = internal.Duration{Duration: 10 * time.Minute}
+			require.NoError(t, tt.rate.Init())
+
+			var actual []telegraf.Metric
+			for _, m := range tt.input {
+				actual = append(actual, tt.rate.Apply(m)...)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestRatePurge(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	r := &Rate{Suffix: "_rate", MaxGap: internal.Duration{Duration: time.Minute}}
+	require.NoError(t, r.Init())
+
+	r.Apply(testutil.MustMetric("net",
+		map[string]string{"interface": "eth1"},
+		map[string]interface{}{"bytes_sent": uint64(1000)},
+		now))
+	r.Apply(netMetric(map[string]interface{}{"bytes_sent": uint64(1000)}, now.Add(30*time.Second)))
+	require.Len(t, r.cache, 2)
+
+	// eth1 is not seen for longer than max_gap.
+	r.Apply(netMetric(map[string]interface{}{"bytes_sent": uint64(2000)}, now.Add(70*time.Second)))
+	require.Len(t, r.cache, 1)
+}
+
+// Apply is called concurrently for the metrics and the aggregations.
+func TestRateConcurrent(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	r := &Rate{Suffix: "_rate", MaxGap: internal.Duration{Duration: time.Minute}}
+	require.NoError(t, r.Init())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(iface string) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Apply(testutil.MustMetric("net",
+					map[string]string{"interface": iface},
+					map[string]interface{}{"bytes_sent": uint64(1000 * j)},
+					now.Add(time.Duration(j)*time.Second)))
+			}
+		}("eth" + strconv.Itoa(i))
+	}
+	wg.Wait()
+	require.Len(t, r.cache, 4)
+}
+
+func TestInitErrors(t *testing.T) {
+	require.Error(t, (&Rate{CounterBits: 16, Suffix: "_rate"}).Init())
+	require.Error(t, (&Rate{}).Init())
+	require.NoError(t, (&Rate{Replace: true}).Init())
+}
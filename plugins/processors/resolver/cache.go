@@ -0,0 +1,124 @@
+package resolver
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// cache holds the results of the lookups by key.  Expired entries are still
+// returned until they are refreshed, so that metrics are not left unresolved
+// while the lookup runs.  Once full, the least recently used entry is
+// replaced by a new key.
+type cache struct {
+	sync.Mutex
+	entries map[string]*list.Element
+	// lru orders the entries from the most to the least recently used.
+	lru  *list.List
+	size int
+}
+
+type cacheEntry struct {
+	key     string
+	value   interface{}
+	ok      bool
+	expires time.Time
+	// pending is set while a lookup of the entry is queued or running.
+	pending bool
+}
+
+func newCache(size int) *cache {
+	return &cache{
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		size:    size,
+	}
+}
+
+// get returns the value of the key if a lookup succeeded, and whether a
+// lookup must be started because the key is missing or expired.  The entry
+// is then pending until set or cancel is called.
+func (c *cache) get(key string, now time.Time) (interface{}, bool, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		if len(c.entries) >= c.size && !c.evict() {
+			return nil, false, false
+		}
+		c.add(&cacheEntry{key: key, pending: true})
+		return nil, false, true
+	}
+
+	c.lru.MoveToFront(elem)
+	e := elem.Value.(*cacheEntry)
+	lookup := !e.pending && !now.Before(e.expires)
+	if lookup {
+		e.pending = true
+	}
+	return e.value, e.ok, lookup
+}
+
+// set stores the result of the lookup of the key.
+func (c *cache) set(key string, value interface{}, ok bool, expires time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, found := c.entries[key]
+	if !found {
+		if len(c.entries) >= c.size && !c.evict() {
+			return
+		}
+		elem = c.add(&cacheEntry{key: key})
+	}
+	e := elem.Value.(*cacheEntry)
+	if ok || !e.ok {
+		// The previous value is kept if the refresh failed.
+		e.value = value
+		e.ok = ok
+	}
+	e.expires = expires
+	e.pending = false
+}
+
+// cancel clears the pending state of the key when its lookup could not be
+// started, so that it is retried.
+func (c *cache) cancel(key string) {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	e := elem.Value.(*cacheEntry)
+	e.pending = false
+	if e.expires.IsZero() {
+		c.remove(elem)
+	}
+}
+
+func (c *cache) add(e *cacheEntry) *list.Element {
+	elem := c.lru.PushFront(e)
+	c.entries[e.key] = elem
+	return elem
+}
+
+func (c *cache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
+
+// evict removes the least recently used entry which is not pending, it
+// returns false if all the entries are pending.  The pending entries are
+// bounded by the lookups queued and running.
+func (c *cache) evict() bool {
+	for elem := c.lru.Back(); elem != nil; elem = elem.Prev() {
+		if !elem.Value.(*cacheEntry).pending {
+			c.remove(elem)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,389 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/soniah/gosnmp"
+)
+
+const sampleConfig = `
+  ## Tags and fields holding IP addresses to resolve to host names.  The
+  ## host name is added as a tag, or a field, named with the suffix.
+  # ip_tags = ["source", "agent_host"]
+  # ip_fields = ["src_ip", "dst_ip"]
+  # hostname_suffix = "_name"
+
+  ## Time the host names are cached for, and timeout of the lookups.
+  # dns_ttl = "1h"
+  # dns_timeout = "2s"
+
+  ## Tag holding an interface index to resolve to the interface name and
+  ## alias, with SNMP queries to the agent in agent_tag.
+  # if_index_tag = "ifIndex"
+  # agent_tag = "agent_host"
+
+  ## Tags the interface name and alias are added as, empty to not add them.
+  # if_name_tag = "ifName"
+  # if_alias_tag = "ifAlias"
+
+  ## Time the interfaces of an agent are cached for.
+  # if_ttl = "1h"
+
+  ## Time failed lookups are cached for.
+  # negative_ttl = "5m"
+
+  ## Maximum number of lookups started per second, and of lookups running at
+  ## the same time.  Lookups not started are retried with the next metrics.
+  # max_lookups_per_second = 100
+  # max_parallel_lookups = 10
+
+  ## Maximum number of host names and of agents cached, the least recently
+  ## used are replaced once the cache is full.
+  # cache_size = 10000
+
+  ## SNMP options of the interface lookups.
+  # version = 2
+  # community = "public"
+  # timeout = "5s"
+  # retries = 3
+  # max_repetitions = 10
+  # sec_name = "myuser"
+  # auth_protocol = "md5"      # Values: "MD5", "SHA", ""
+  # auth_password = "pass"
+  # sec_level = "authNoPriv"   # Values: "noAuthNoPriv", "authNoPriv", "authPriv"
+  # context_name = ""
+  # priv_protocol = ""         # Values: "DES", "AES", ""
+  # priv_password = ""
+`
+
+const (
+	ifNameOID  = ".1.3.6.1.2.1.31.1.1.1.1"
+	ifAliasOID = ".1.3.6.1.2.1.31.1.1.1.18"
+
+	// queueSize is the number of lookups waiting to be started, lookups are
+	// not queued when it is full.
+	queueSize = 1000
+
+	// maxLookupsPerSecond keeps the interval between lookups at least a
+	// nanosecond.
+	maxLookupsPerSecond = int(time.Second)
+)
+
+type Resolver struct {
+	IPTags         []string          `toml:"ip_tags"`
+	IPFields       []string          `toml:"ip_fields"`
+	HostnameSuffix string            `toml:"hostname_suffix"`
+	DNSTTL         internal.Duration `toml:"dns_ttl"`
+	DNSTimeout     internal.Duration `toml:"dns_timeout"`
+
+	IfIndexTag string            `toml:"if_index_tag"`
+	AgentTag   string            `toml:"agent_tag"`
+	IfNameTag  string            `toml:"if_name_tag"`
+	IfAliasTag string            `toml:"if_alias_tag"`
+	IfTTL      internal.Duration `toml:"if_ttl"`
+
+	NegativeTTL         internal.Duration `toml:"negative_ttl"`
+	MaxLookupsPerSecond int               `toml:"max_lookups_per_second"`
+	MaxParallelLookups  int               `toml:"max_parallel_lookups"`
+	CacheSize           int               `toml:"cache_size"`
+
+	snmp.ClientConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	hostnames  *cache
+	interfaces *cache
+	queue      chan request
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// lookupAddr and walkInterfaces run the lookups, they are replaced in
+	// tests.
+	lookupAddr     func(ctx context.Context, addr string) ([]string, error)
+	walkInterfaces func(agent string) (map[string]ifNames, error)
+}
+
+// request is a lookup of a host name, or of the interfaces of an agent.
+type request struct {
+	key   string
+	agent bool
+}
+
+// ifNames is the name and alias of an interface.
+type ifNames struct {
+	name  string
+	alias string
+}
+
+func (r *Resolver) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Resolver) Description() string {
+	return "Resolve IP addresses to host names and interface indexes to names"
+}
+
+func (r *Resolver) Init() error {
+	if len(r.IPTags) == 0 && len(r.IPFields) == 0 && r.IfIndexTag == "" {
+		return errors.New("at least one of ip_tags, ip_fields or if_index_tag is required")
+	}
+	if r.HostnameSuffix == "" && (len(r.IPTags) > 0 || len(r.IPFields) > 0) {
+		return errors.New("hostname_suffix is required")
+	}
+	if r.IfIndexTag != "" && r.AgentTag == "" {
+		return errors.New("agent_tag is required with if_index_tag")
+	}
+	if r.MaxLookupsPerSecond <= 0 || r.MaxParallelLookups <= 0 || r.CacheSize <= 0 {
+		return errors.New("max_lookups_per_second, max_parallel_lookups and cache_size must be positive")
+	}
+	if r.MaxLookupsPerSecond > maxLookupsPerSecond {
+		return fmt.Errorf("max_lookups_per_second must be at most %d", maxLookupsPerSecond)
+	}
+
+	r.hostnames = newCache(r.CacheSize)
+	r.interfaces = newCache(r.CacheSize)
+	r.queue = make(chan request, queueSize)
+	if r.lookupAddr == nil {
+		r.lookupAddr = net.DefaultResolver.LookupAddr
+	}
+	if r.walkInterfaces == nil {
+		r.walkInterfaces = r.walk
+	}
+	return nil
+}
+
+// Start starts the lookups, the metrics are only resolved from the cache
+// until then.
+func (r *Resolver) Start(acc telegraf.Accumulator) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	ticker := time.NewTicker(time.Second / time.Duration(r.MaxLookupsPerSecond))
+	r.wg.Add(r.MaxParallelLookups)
+	for i := 0; i < r.MaxParallelLookups; i++ {
+		go func() {
+			defer r.wg.Done()
+			r.run(ctx, ticker.C)
+		}()
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		<-ctx.Done()
+		ticker.Stop()
+	}()
+	return nil
+}
+
+func (r *Resolver) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Apply adds the resolved names to the metrics.  It never waits for the
+// lookups: names not cached yet are added to the next metrics once the
+// lookup is done.
+func (r *Resolver) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+	for _, m := range in {
+		for _, key := range r.IPTags {
+			if ip, ok := m.GetTag(key); ok {
+				if name, ok := r.hostname(ip, now); ok {
+					m.AddTag(key+r.HostnameSuffix, name)
+				}
+			}
+		}
+		for _, key := range r.IPFields {
+			if v, ok := m.GetField(key); ok {
+				if ip, ok := v.(string); ok {
+					if name, ok := r.hostname(ip, now); ok {
+						m.AddField(key+r.HostnameSuffix, name)
+					}
+				}
+			}
+		}
+		if r.IfIndexTag != "" {
+			r.resolveInterface(m, now)
+		}
+	}
+	return in
+}
+
+func (r *Resolver) hostname(ip string, now time.Time) (string, bool) {
+	if net.ParseIP(ip) == nil {
+		return "", false
+	}
+	v, ok := r.get(r.hostnames, request{key: ip}, now)
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+func (r *Resolver) resolveInterface(m telegraf.Metric, now time.Time) {
+	index, ok := m.GetTag(r.IfIndexTag)
+	if !ok {
+		return
+	}
+	agent, ok := m.GetTag(r.AgentTag)
+	if !ok {
+		return
+	}
+
+	v, ok := r.get(r.interfaces, request{key: agent, agent: true}, now)
+	if !ok {
+		return
+	}
+	names, ok := v.(map[string]ifNames)[index]
+	if !ok {
+		return
+	}
+	if r.IfNameTag != "" && names.name != "" {
+		m.AddTag(r.IfNameTag, names.name)
+	}
+	if r.IfAliasTag != "" && names.alias != "" {
+		m.AddTag(r.IfAliasTag, names.alias)
+	}
+}
+
+// get returns the cached value of the request, and queues a lookup if it is
+// missing or expired.
+func (r *Resolver) get(c *cache, req request, now time.Time) (interface{}, bool) {
+	v, ok, lookup := c.get(req.key, now)
+	if lookup {
+		// Lookups only run once started, and are dropped when too many are
+		// waiting.
+		queued := false
+		if r.cancel != nil {
+			select {
+			case r.queue <- req:
+				queued = true
+			default:
+			}
+		}
+		if !queued {
+			c.cancel(req.key)
+		}
+	}
+	return v, ok
+}
+
+// run runs the queued lookups, waiting for a tick before each.
+func (r *Resolver) run(ctx context.Context, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-r.queue:
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			}
+			if req.agent {
+				r.lookupInterfaces(req.key)
+			} else {
+				r.lookupHostname(ctx, req.key)
+			}
+		}
+	}
+}
+
+func (r *Resolver) lookupHostname(ctx context.Context, ip string) {
+	ctx, cancel := context.WithTimeout(ctx, r.DNSTimeout.Duration)
+	defer cancel()
+
+	names, err := r.lookupAddr(ctx, ip)
+	if err == nil && len(names) == 0 {
+		err = errors.New("no host name")
+	}
+	if err != nil {
+		r.Log.Debugf("Could not resolve %s: %v", ip, err)
+		r.hostnames.set(ip, nil, false, time.Now().Add(r.NegativeTTL.Duration))
+		return
+	}
+	name := strings.TrimSuffix(names[0], ".")
+	r.hostnames.set(ip, name, true, time.Now().Add(r.DNSTTL.Duration))
+}
+
+func (r *Resolver) lookupInterfaces(agent string) {
+	interfaces, err := r.walkInterfaces(agent)
+	if err != nil {
+		r.Log.Debugf("Could not get the interfaces of %s: %v", agent, err)
+		r.interfaces.set(agent, nil, false, time.Now().Add(r.NegativeTTL.Duration))
+		return
+	}
+	r.interfaces.set(agent, interfaces, true, time.Now().Add(r.IfTTL.Duration))
+}
+
+// walk returns the names and aliases of the interfaces of the agent by
+// index, from the IF-MIB ifXTable.
+func (r *Resolver) walk(agent string) (map[string]ifNames, error) {
+	gs, err := snmp.NewWrapper(r.ClientConfig, agent)
+	if err != nil {
+		return nil, err
+	}
+	if err := gs.Connect(); err != nil {
+		return nil, err
+	}
+	defer gs.Conn.Close()
+
+	interfaces := make(map[string]ifNames)
+	for _, oid := range []string{ifNameOID, ifAliasOID} {
+		err := gs.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+			index := strings.TrimPrefix(pdu.Name, oid+".")
+			value, ok := pdu.Value.([]byte)
+			if !ok {
+				return nil
+			}
+			names := interfaces[index]
+			if oid == ifNameOID {
+				names.name = string(value)
+			} else {
+				names.alias = string(value)
+			}
+			interfaces[index] = names
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return interfaces, nil
+}
+
+func init() {
+	processors.Add("resolver", func() telegraf.Processor {
+		return &Resolver{
+			HostnameSuffix:      "_name",
+			DNSTTL:              internal.Duration{Duration: time.Hour},
+			DNSTimeout:          internal.Duration{Duration: 2 * time.Second},
+			IfNameTag:           "ifName",
+			IfAliasTag:          "ifAlias",
+			IfTTL:               internal.Duration{Duration: time.Hour},
+			NegativeTTL:         internal.Duration{Duration: 5 * time.Minute},
+			MaxLookupsPerSecond: 100,
+			MaxParallelLookups:  10,
+			CacheSize:           10000,
+			ClientConfig: snmp.ClientConfig{
+				Timeout:        internal.Duration{Duration: 5 * time.Second},
+				Retries:        3,
+				Version:        2,
+				Community:      "public",
+				MaxRepetitions: 10,
+			},
+		}
+	})
+}
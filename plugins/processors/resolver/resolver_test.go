@@ -0,0 +1,258 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newResolver() *Resolver {
+	return &Resolver{
+		HostnameSuffix:      "_name",
+		DNSTTL:              internal.Duration{Duration: time.Hour},
+		DNSTimeout:          internal.Duration{Duration: time.Second},
+		IfNameTag:           "ifName",
+		IfAliasTag:          "ifAlias",
+		IfTTL:               internal.Duration{Duration: time.Hour},
+		NegativeTTL:         internal.Duration{Duration: time.Hour},
+		MaxLookupsPerSecond: 1000,
+		MaxParallelLookups:  2,
+		CacheSize:           100,
+		Log:                 testutil.Logger{},
+		lookupAddr: func(ctx context.Context, addr string) ([]string, error) {
+			switch addr {
+			case "10.0.0.1":
+				return []string{"db01.example.com."}, nil
+			case "10.0.0.2":
+				return []string{"web01.example.com."}, nil
+			}
+			return nil, errors.New("not found")
+		},
+		walkInterfaces: func(agent string) (map[string]ifNames, error) {
+			if agent != "10.0.0.1" {
+				return nil, errors.New("timeout")
+			}
+			return map[string]ifNames{
+				"1": {name: "eth0", alias: "uplink"},
+				"2": {name: "eth1"},
+			}, nil
+		},
+	}
+}
+
+// applyUntil applies the metric until it is resolved, as the lookups run in
+// the background.
+func applyUntil(t *testing.T, r *Resolver, m telegraf.Metric, resolved func(telegraf.Metric) bool) telegraf.Metric {
+	for i := 0; i < 200; i++ {
+		out := r.Apply(m.Copy())
+		require.Len(t, out, 1)
+		if resolved(out[0]) {
+			return out[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("metric was not resolved")
+	return nil
+}
+
+func TestResolveHostnames(t *testing.T) {
+	r := newResolver()
+	r.IPTags = []string{"source"}
+	r.IPFields = []string{"src_ip"}
+	require.NoError(t, r.Init())
+	require.NoError(t, r.Start(nil))
+	defer r.Stop()
+
+	m := testutil.MustMetric("netflow",
+		map[string]string{"source": "10.0.0.1"},
+		map[string]interface{}{"src_ip": "10.0.0.2", "bytes": int64(42)},
+		time.Unix(0, 0))
+
+	// The first metrics are passed before the lookups are done.
+	actual := r.Apply(m.Copy())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, actual)
+
+	actual = []telegraf.Metric{applyUntil(t, r, m, func(m telegraf.Metric) bool {
+		return m.HasTag("source_name") && m.HasField("src_ip_name")
+	})}
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric("netflow",
+			map[string]string{"source": "10.0.0.1", "source_name": "db01.example.com"},
+			map[string]interface{}{"src_ip": "10.0.0.2", "src_ip_name": "web01.example.com", "bytes": int64(42)},
+			time.Unix(0, 0)),
+	}, actual)
+}
+
+func TestResolveFailures(t *testing.T) {
+	r := newResolver()
+	r.IPTags = []string{"source"}
+	require.NoError(t, r.Init())
+	require.NoError(t, r.Start(nil))
+	defer r.Stop()
+
+	for _, ip := range []string{"10.0.0.9", "not an ip"} {
+		m := testutil.MustMetric("netflow",
+			map[string]string{"source": ip},
+			map[string]interface{}{"bytes": int64(42)},
+			time.Unix(0, 0))
+		r.Apply(m.Copy())
+		time.Sleep(50 * time.Millisecond)
+		actual := r.Apply(m.Copy())
+		testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, actual)
+	}
+}
+
+func TestResolveInterfaces(t *testing.T) {
+	r := newResolver()
+	r.IfIndexTag = "ifIndex"
+	r.AgentTag = "agent_host"
+	require.NoError(t, r.Init())
+	require.NoError(t, r.Start(nil))
+	defer r.Stop()
+
+	m := testutil.MustMetric("interface",
+		map[string]string{"agent_host": "10.0.0.1", "ifIndex": "1"},
+		map[string]interface{}{"ifHCInOctets": int64(42)},
+		time.Unix(0, 0))
+	actual := applyUntil(t, r, m, func(m telegraf.Metric) bool {
+		return m.HasTag("ifName")
+	})
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric("interface",
+			map[string]string{"agent_host": "10.0.0.1", "ifIndex": "1", "ifName": "eth0", "ifAlias": "uplink"},
+			map[string]interface{}{"ifHCInOctets": int64(42)},
+			time.Unix(0, 0)),
+	}, []telegraf.Metric{actual})
+
+	m = testutil.MustMetric("interface",
+		map[string]string{"agent_host": "10.0.0.1", "ifIndex": "2"},
+		map[string]interface{}{"ifHCInOctets": int64(42)},
+		time.Unix(0, 0))
+	actual = r.Apply(m.Copy())[0]
+	require.Equal(t, map[string]string{"agent_host": "10.0.0.1", "ifIndex": "2", "ifName": "eth1"}, actual.Tags())
+}
+
+func TestApplyNeverBlocks(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	r := newResolver()
+	r.IPTags = []string{"source"}
+	r.CacheSize = 4 * queueSize
+	r.lookupAddr = func(ctx context.Context, addr string) ([]string, error) {
+		select {
+		case <-block:
+		case <-ctx.Done():
+		}
+		return nil, errors.New("blocked")
+	}
+	require.NoError(t, r.Init())
+	require.NoError(t, r.Start(nil))
+	defer r.Stop()
+
+	// More addresses than the lookups running and queued.
+	start := time.Now()
+	for i := 0; i < 2*queueSize; i++ {
+		m := testutil.MustMetric("netflow",
+			map[string]string{"source": fmt.Sprintf("10.1.%d.%d", i/256, i%256)},
+			map[string]interface{}{"bytes": int64(42)},
+			time.Unix(0, 0))
+		r.Apply(m)
+	}
+	require.True(t, time.Since(start) < time.Second)
+}
+
+func TestNotStarted(t *testing.T) {
+	r := newResolver()
+	r.IPTags = []string{"source"}
+	require.NoError(t, r.Init())
+
+	m := testutil.MustMetric("netflow",
+		map[string]string{"source": "10.0.0.1"},
+		map[string]interface{}{"bytes": int64(42)},
+		time.Unix(0, 0))
+	actual := r.Apply(m.Copy())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, actual)
+	require.Empty(t, r.hostnames.entries)
+}
+
+func TestCache(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	c := newCache(2)
+
+	_, ok, lookup := c.get("a", now)
+	require.False(t, ok)
+	require.True(t, lookup)
+
+	// Pending entries are not looked up again.
+	_, _, lookup = c.get("a", now)
+	require.False(t, lookup)
+
+	c.set("a", "x", true, now.Add(time.Minute))
+	v, ok, lookup := c.get("a", now)
+	require.Equal(t, "x", v)
+	require.True(t, ok)
+	require.False(t, lookup)
+
+	// Expired entries are returned while refreshed, and kept if the refresh
+	// fails.
+	v, ok, lookup = c.get("a", now.Add(time.Minute))
+	require.Equal(t, "x", v)
+	require.True(t, ok)
+	require.True(t, lookup)
+	c.set("a", nil, false, now.Add(2*time.Minute))
+	v, ok, _ = c.get("a", now.Add(time.Minute))
+	require.Equal(t, "x", v)
+	require.True(t, ok)
+
+	// Canceled lookups are retried.
+	_, _, lookup = c.get("b", now)
+	require.True(t, lookup)
+	c.cancel("b")
+	_, _, lookup = c.get("b", now)
+	require.True(t, lookup)
+
+	// Once full, the least recently used entry which is not pending is
+	// replaced.
+	c.set("b", "y", true, now.Add(time.Minute))
+	c.get("a", now)
+	_, _, lookup = c.get("c", now)
+	require.True(t, lookup)
+	require.Len(t, c.entries, 2)
+	v, _, _ = c.get("a", now)
+	require.Equal(t, "x", v)
+	_, ok, lookup = c.get("b", now)
+	require.False(t, ok)
+	require.True(t, lookup)
+
+	// New keys are not cached while all the entries are pending.
+	_, _, lookup = c.get("d", now)
+	require.False(t, lookup)
+	require.Len(t, c.entries, 2)
+}
+
+func TestInitErrors(t *testing.T) {
+	r := newResolver()
+	require.Error(t, r.Init())
+
+	r = newResolver()
+	r.IfIndexTag = "ifIndex"
+	require.Error(t, r.Init())
+
+	r = newResolver()
+	r.IPTags = []string{"source"}
+	r.MaxParallelLookups = 0
+	require.Error(t, r.Init())
+
+	r = newResolver()
+	r.IPTags = []string{"source"}
+	r.MaxLookupsPerSecond = int(time.Second) + 1
+	require.Error(t, r.Init())
+}
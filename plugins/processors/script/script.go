@@ -0,0 +1,449 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const sampleConfig = `
+  ## Lua source of the script, it must define a function apply(metric)
+  ## returning the metric, a list of metrics or nil to drop the metric.
+  # source = '''
+  # function apply(metric)
+  #   if metric.fields.usage_idle ~= nil and metric.fields.usage_idle < 10 then
+  #     metric.tags.severity = "critical"
+  #   end
+  #   return metric
+  # end
+  # '''
+
+  ## File containing the script, instead of source.
+  # script = "/etc/telegraf/script.lua"
+
+  ## Maximum time a call to apply may run for.
+  # timeout = "1s"
+`
+
+// Globals removed from the sandbox: file and module loading, and random
+// numbers to keep the scripts deterministic.
+var (
+	removedGlobals = []string{
+		"dofile", "loadfile", "load", "loadstring", "require", "module",
+		"collectgarbage", "newproxy", "_printregs",
+	}
+	removedMath = []string{"random", "randomseed"}
+)
+
+type Script struct {
+	Source  string            `toml:"source"`
+	Script  string            `toml:"script"`
+	Timeout internal.Duration `toml:"timeout"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	// The Lua state is not safe for concurrent use, while Apply is called
+	// for both the gathered metrics and the aggregations.
+	mu    sync.Mutex
+	state *lua.LState
+	apply *lua.LFunction
+}
+
+func (s *Script) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Script) Description() string {
+	return "Process metrics with a sandboxed Lua script"
+}
+
+func (s *Script) Init() error {
+	if (s.Source == "") == (s.Script == "") {
+		return errors.New("exactly one of source or script must be set")
+	}
+
+	source := s.Source
+	name := "source"
+	if s.Script != "" {
+		b, err := ioutil.ReadFile(s.Script)
+		if err != nil {
+			return err
+		}
+		source = string(b)
+		name = s.Script
+	}
+
+	s.state = s.newState()
+	fn, err := s.state.Load(strings.NewReader(source), name)
+	if err != nil {
+		return err
+	}
+	s.state.Push(fn)
+	if err := s.call(0, 0); err != nil {
+		return err
+	}
+
+	apply, ok := s.state.GetGlobal("apply").(*lua.LFunction)
+	if !ok {
+		return fmt.Errorf("%s: function apply(metric) is not defined", name)
+	}
+	s.apply = apply
+	return nil
+}
+
+// newState returns a Lua state with only the base, table, string and math
+// libraries, without access to files or the network.
+func (s *Script) newState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+
+	for _, name := range removedGlobals {
+		L.SetGlobal(name, lua.LNil)
+	}
+	if mathlib, ok := L.GetGlobal(lua.MathLibName).(*lua.LTable); ok {
+		for _, name := range removedMath {
+			mathlib.RawSetString(name, lua.LNil)
+		}
+	}
+
+	L.SetGlobal("print", L.NewFunction(func(L *lua.LState) int {
+		var msg string
+		for i := 1; i <= L.GetTop(); i++ {
+			if i > 1 {
+				msg += "\t"
+			}
+			msg += L.ToStringMeta(L.Get(i)).String()
+		}
+		s.Log.Info(msg)
+		return 0
+	}))
+	return L
+}
+
+// call calls the function on the stack with the timeout.
+func (s *Script) call(nargs, nret int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout.Duration)
+	defer cancel()
+
+	s.state.SetContext(ctx)
+	defer s.state.RemoveContext()
+	return s.state.PCall(nargs, nret, nil)
+}
+
+func (s *Script) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		table := s.toTable(m)
+		s.state.Push(s.apply)
+		s.state.Push(table)
+		if err := s.call(1, 1); err != nil {
+			s.Log.Errorf("Error in apply, passing the metric unchanged: %v", err)
+			out = append(out, m)
+			continue
+		}
+		ret := s.state.Get(-1)
+		s.state.Pop(1)
+
+		metrics, err := s.fromResult(m, table, ret)
+		if err != nil {
+			s.Log.Errorf("Invalid result of apply, passing the metric unchanged: %v", err)
+			out = append(out, m)
+			continue
+		}
+		out = append(out, metrics...)
+	}
+	return out
+}
+
+// toTable returns the metric as a table with name, tags, fields and time in
+// seconds.
+func (s *Script) toTable(m telegraf.Metric) *lua.LTable {
+	L := s.state
+
+	tags := L.NewTable()
+	for _, tag := range m.TagList() {
+		tags.RawSetString(tag.Key, lua.LString(tag.Value))
+	}
+	fields := L.NewTable()
+	for _, field := range m.FieldList() {
+		if v := toValue(field.Value); v != lua.LNil {
+			fields.RawSetString(field.Key, v)
+		}
+	}
+
+	table := L.NewTable()
+	table.RawSetString("name", lua.LString(m.Name()))
+	table.RawSetString("tags", tags)
+	table.RawSetString("fields", fields)
+	table.RawSetString("time", toSeconds(m.Time()))
+	return table
+}
+
+// fromResult returns the metrics returned by apply.  The metric passed to
+// apply is updated if it is returned, and dropped otherwise.
+func (s *Script) fromResult(m telegraf.Metric, table *lua.LTable, ret lua.LValue) ([]telegraf.Metric, error) {
+	var tables []*lua.LTable
+	switch ret := ret.(type) {
+	case *lua.LNilType:
+	case *lua.LTable:
+		if ret.RawGetString("name") != lua.LNil {
+			tables = append(tables, ret)
+			break
+		}
+		for i := 1; i <= ret.Len(); i++ {
+			t, ok := ret.RawGetInt(i).(*lua.LTable)
+			if !ok {
+				return nil, fmt.Errorf("item %d is a %s, not a metric", i, ret.RawGetInt(i).Type())
+			}
+			tables = append(tables, t)
+		}
+	default:
+		return nil, fmt.Errorf("returned a %s, not a metric", ret.Type())
+	}
+
+	// All the results are validated before any metric is changed, so that
+	// the metric is passed unchanged on an invalid result.
+	type update struct {
+		m telegraf.Metric
+		r *result
+	}
+	var updates []update
+	var kept bool
+	for _, t := range tables {
+		target := m
+		if t != table || kept {
+			// New metrics have the type and time of the metric by default.
+			nm, err := metric.New("", nil, nil, m.Time(), m.Type())
+			if err != nil {
+				return nil, err
+			}
+			target = nm
+		} else {
+			kept = true
+		}
+
+		r, err := parseResult(target, t)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, update{m: target, r: r})
+	}
+
+	out := make([]telegraf.Metric, 0, len(updates))
+	for _, u := range updates {
+		u.r.apply(u.m)
+		out = append(out, u.m)
+	}
+	if !kept {
+		m.Drop()
+	}
+	return out, nil
+}
+
+// result is a metric returned by apply.
+type result struct {
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+	// time is nil if unchanged.
+	time *time.Time
+}
+
+// parseResult validates the table returned for the metric and returns its
+// name, tags, fields and time.
+func parseResult(m telegraf.Metric, t *lua.LTable) (*result, error) {
+	name, ok := t.RawGetString("name").(lua.LString)
+	if !ok || name == "" {
+		return nil, errors.New("metric has no name")
+	}
+
+	tags, err := stringMap(t.RawGetString("tags"))
+	if err != nil {
+		return nil, fmt.Errorf("tags: %v", err)
+	}
+
+	fields, ok := t.RawGetString("fields").(*lua.LTable)
+	if !ok {
+		return nil, errors.New("metric has no fields")
+	}
+	values := make(map[string]interface{})
+	var ferr error
+	fields.ForEach(func(k, v lua.LValue) {
+		key, ok := k.(lua.LString)
+		if !ok {
+			ferr = fmt.Errorf("field key %v is not a string", k)
+			return
+		}
+		old, _ := m.GetField(string(key))
+		value, err := fromValue(v, old)
+		if err != nil {
+			ferr = fmt.Errorf("field %s: %v", key, err)
+			return
+		}
+		values[string(key)] = value
+	})
+	if ferr != nil {
+		return nil, ferr
+	}
+	if len(values) == 0 {
+		return nil, errors.New("metric has no fields")
+	}
+
+	r := &result{name: string(name), tags: tags, fields: values}
+
+	// The time is only set if changed, seconds are not precise enough to
+	// keep nanoseconds.
+	switch ts := t.RawGetString("time").(type) {
+	case lua.LNumber:
+		if ts != toSeconds(m.Time()) {
+			sec, frac := math.Modf(float64(ts))
+			tm := time.Unix(int64(sec), int64(frac*1e9))
+			r.time = &tm
+		}
+	case *lua.LNilType:
+	default:
+		return nil, fmt.Errorf("time is a %s, not a number", ts.Type())
+	}
+	return r, nil
+}
+
+// apply sets the name, tags, fields and time of the metric.
+func (r *result) apply(m telegraf.Metric) {
+	m.SetName(r.name)
+
+	var removed []string
+	for _, tag := range m.TagList() {
+		if _, ok := r.tags[tag.Key]; !ok {
+			removed = append(removed, tag.Key)
+		}
+	}
+	for _, k := range removed {
+		m.RemoveTag(k)
+	}
+	for k, v := range r.tags {
+		m.AddTag(k, v)
+	}
+
+	removed = removed[:0]
+	for _, field := range m.FieldList() {
+		if _, ok := r.fields[field.Key]; !ok {
+			removed = append(removed, field.Key)
+		}
+	}
+	for _, k := range removed {
+		m.RemoveField(k)
+	}
+	for k, v := range r.fields {
+		m.AddField(k, v)
+	}
+
+	if r.time != nil {
+		m.SetTime(*r.time)
+	}
+}
+
+func stringMap(v lua.LValue) (map[string]string, error) {
+	result := make(map[string]string)
+	if v == lua.LNil {
+		return result, nil
+	}
+	t, ok := v.(*lua.LTable)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a table", v.Type())
+	}
+
+	var err error
+	t.ForEach(func(k, v lua.LValue) {
+		switch v.(type) {
+		case lua.LString, lua.LNumber, lua.LBool:
+			result[k.String()] = v.String()
+		default:
+			err = fmt.Errorf("value of %s is a %s", k, v.Type())
+		}
+	})
+	return result, err
+}
+
+func toValue(v interface{}) lua.LValue {
+	switch v := v.(type) {
+	case float64:
+		return lua.LNumber(v)
+	case int64:
+		return lua.LNumber(v)
+	case uint64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case bool:
+		return lua.LBool(v)
+	default:
+		return lua.LNil
+	}
+}
+
+// fromValue converts a Lua value to a field value.  Numbers keep the type
+// of the previous value of the field, and are floats for new fields.
+func fromValue(v lua.LValue, old interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case lua.LNumber:
+		if toValue(old) == v {
+			return old, nil
+		}
+		f := float64(v)
+		switch old.(type) {
+		case int64:
+			if f == math.Trunc(f) {
+				return int64(f), nil
+			}
+		case uint64:
+			if f == math.Trunc(f) && f >= 0 {
+				return uint64(f), nil
+			}
+		}
+		return f, nil
+	case lua.LString:
+		return string(v), nil
+	case lua.LBool:
+		return bool(v), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", v.Type())
+	}
+}
+
+func toSeconds(t time.Time) lua.LNumber {
+	return lua.LNumber(float64(t.UnixNano()) / 1e9)
+}
+
+func init() {
+	processors.Add("script", func() telegraf.Processor {
+		return &Script{
+			Timeout: internal.Duration{Duration: time.Second},
+		}
+	})
+}
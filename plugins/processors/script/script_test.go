@@ -0,0 +1,375 @@
+package script
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScript(source string) *Script {
+	return &Script{
+		Source:  source,
+		Timeout: internal.Duration{Duration: time.Second},
+		Log:     testutil.Logger{},
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1500000000, 123456789)
+
+	tests := []struct {
+		name     string
+		source   string
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "unchanged",
+			source: `
+function apply(metric)
+  return metric
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"idle": int64(42), "user": 1.5, "ok": true, "state": "up"},
+					now),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"idle": int64(42), "user": 1.5, "ok": true, "state": "up"},
+					now),
+			},
+		},
+		{
+			name: "tag and drop field on condition",
+			source: `
+function apply(metric)
+  if metric.fields.usage > 90 then
+    metric.tags.severity = "critical"
+    metric.fields.detail = nil
+  end
+  return metric
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"usage": int64(95), "detail": "x"},
+					now),
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"usage": int64(10), "detail": "x"},
+					now),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"severity": "critical"},
+					map[string]interface{}{"usage": int64(95)},
+					now),
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"usage": int64(10), "detail": "x"},
+					now),
+			},
+		},
+		{
+			name: "rename and convert",
+			source: `
+function apply(metric)
+  metric.name = "mem"
+  metric.tags.host = nil
+  metric.fields.used = metric.fields.used / 2
+  metric.fields.total = 100
+  metric.time = 10
+  return metric
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("memory",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"used": int64(5)},
+					now),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("mem",
+					map[string]string{},
+					map[string]interface{}{"used": 2.5, "total": 100.0},
+					time.Unix(10, 0)),
+			},
+		},
+		{
+			name: "drop",
+			source: `
+function apply(metric)
+  return nil
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 42},
+					now),
+			},
+			expected: []telegraf.Metric{},
+		},
+		{
+			name: "emit several metrics",
+			source: `
+function apply(metric)
+  local total = {
+    name = metric.name .. "_total",
+    tags = {},
+    fields = {value = metric.fields.value},
+  }
+  return {metric, total}
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"cpu": "0"},
+					map[string]interface{}{"value": 42.0},
+					now),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"cpu": "0"},
+					map[string]interface{}{"value": 42.0},
+					now),
+				testutil.MustMetric("cpu_total",
+					map[string]string{},
+					map[string]interface{}{"value": 42.0},
+					now),
+			},
+		},
+		{
+			name: "state between calls",
+			source: `
+local count = 0
+function apply(metric)
+  count = count + 1
+  metric.fields.count = count
+  return metric
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 42.0},
+					now),
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 43.0},
+					now),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 42.0, "count": 1.0},
+					now),
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 43.0, "count": 2.0},
+					now),
+			},
+		},
+		{
+			name: "error passes metric unchanged",
+			source: `
+function apply(metric)
+  error("boom")
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 42.0},
+					now),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 42.0},
+					now),
+			},
+		},
+		{
+			name: "invalid result passes metric unchanged",
+			source: `
+function apply(metric)
+  metric.name = "renamed"
+  metric.tags.host = "b"
+  metric.fields.value = {1, 2}
+  return metric
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0},
+					now),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0},
+					now),
+			},
+		},
+		{
+			name: "invalid new metric passes metric unchanged",
+			source: `
+function apply(metric)
+  metric.name = "renamed"
+  local extra = {name = "extra", fields = {value = {}}}
+  return {metric, extra}
+end
+`,
+			input: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0},
+					now),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0},
+					now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScript(tt.source)
+			require.NoError(t, s.Init())
+
+			actual := s.Apply(tt.input...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script *Script
+	}{
+		{
+			name:   "no source",
+			script: newScript(""),
+		},
+		{
+			name:   "no apply function",
+			script: newScript("x = 1"),
+		},
+		{
+			name:   "syntax error",
+			script: newScript("function apply("),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.script.Init())
+		})
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	for _, call := range []string{
+		`io.open("/etc/passwd")`,
+		`os.execute("true")`,
+		`dofile("/etc/passwd")`,
+		`require("os")`,
+		`loadstring("return 1")`,
+		`math.random()`,
+	} {
+		t.Run(call, func(t *testing.T) {
+			s := newScript("function apply(metric) " + call + " return metric end")
+			require.NoError(t, s.Init())
+
+			m := testutil.MustMetric("cpu",
+				map[string]string{},
+				map[string]interface{}{"value": 42.0},
+				time.Unix(0, 0))
+			actual := s.Apply(m)
+
+			// The call fails and the metric is passed unchanged.
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, actual)
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	s := newScript("function apply(metric) while true do end end")
+	s.Timeout = internal.Duration{Duration: 100 * time.Millisecond}
+	require.NoError(t, s.Init())
+
+	m := testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"value": 42.0},
+		time.Unix(0, 0))
+	actual := s.Apply(m)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, actual)
+}
+
+// Apply is called concurrently for the metrics and the aggregations.
+func TestApplyConcurrent(t *testing.T) {
+	s := newScript("function apply(metric) metric.fields.value = metric.fields.value * 2 return metric end")
+	require.NoError(t, s.Init())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m := testutil.MustMetric("cpu",
+					map[string]string{},
+					map[string]interface{}{"value": float64(j)},
+					time.Unix(0, 0))
+				actual := s.Apply(m)
+				if assert.Len(t, actual, 1) {
+					value, _ := actual[0].GetField("value")
+					assert.Equal(t, float64(2*j), value)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestScriptFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "script")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("function apply(metric) metric.tags.file = 'yes' return metric end")
+	require.NoError(t, err)
+	f.Close()
+
+	s := newScript("")
+	s.Script = f.Name()
+	require.NoError(t, s.Init())
+
+	actual := s.Apply(testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"value": 42.0},
+		time.Unix(0, 0)))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"file": "yes"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0)),
+	}, actual)
+}
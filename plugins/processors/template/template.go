@@ -0,0 +1,111 @@
+package template
+
+import (
+	"errors"
+	"strings"
+	"text/template"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Tag set to the result of the template.
+  tag = "topic"
+
+  ## Set the measurement name to the result of the template, instead of a
+  ## tag.
+  # measurement = false
+
+  ## Go template, see https://golang.org/pkg/text/template.  The methods
+  ## Name, Tag, Field, HasTag, HasField and Time return the name, tags,
+  ## fields and time of the metric, and the functions lower, upper,
+  ## replace, trimPrefix and trimSuffix transform strings.
+  template = '{{.Tag "host"}}-{{.Tag "interface"}}'
+`
+
+// funcs are the functions available to the templates, in addition to the
+// builtin functions.
+var funcs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+}
+
+type Template struct {
+	Tag         string `toml:"tag"`
+	Measurement bool   `toml:"measurement"`
+	Template    string `toml:"template"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	tmpl *template.Template
+}
+
+// metric is the data of the templates, giving access to the metric.
+type metric struct {
+	telegraf.Metric
+}
+
+// Tag returns the value of the tag, or an empty string if the metric does
+// not have it.
+func (m metric) Tag(key string) string {
+	v, _ := m.GetTag(key)
+	return v
+}
+
+// Field returns the value of the field, or nil if the metric does not have
+// it.
+func (m metric) Field(key string) interface{} {
+	v, _ := m.GetField(key)
+	return v
+}
+
+func (t *Template) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Template) Description() string {
+	return "Set a tag or the measurement name from a template of the metric"
+}
+
+func (t *Template) Init() error {
+	if (t.Tag == "") == !t.Measurement {
+		return errors.New("exactly one of tag or measurement must be set")
+	}
+
+	var err error
+	t.tmpl, err = template.New("template").Funcs(funcs).Option("missingkey=zero").Parse(t.Template)
+	return err
+}
+
+func (t *Template) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	var b strings.Builder
+	for _, m := range in {
+		b.Reset()
+		if err := t.tmpl.Execute(&b, metric{m}); err != nil {
+			t.Log.Errorf("Could not execute template for %s: %v", m.Name(), err)
+			continue
+		}
+
+		// Empty values are not valid names or tags.
+		value := b.String()
+		if value == "" {
+			continue
+		}
+		if t.Measurement {
+			m.SetName(value)
+		} else {
+			m.AddTag(t.Tag, value)
+		}
+	}
+	return in
+}
+
+func init() {
+	processors.Add("template", func() telegraf.Processor {
+		return &Template{}
+	})
+}
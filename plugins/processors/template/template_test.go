@@ -0,0 +1,84 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	input := func() telegraf.Metric {
+		return testutil.MustMetric("net",
+			map[string]string{"host": "db01", "interface": "eth0"},
+			map[string]interface{}{"bytes_sent": int64(42), "speed": int64(0)},
+			now)
+	}
+
+	tests := []struct {
+		name     string
+		template *Template
+		expected telegraf.Metric
+	}{
+		{
+			name:     "tag from tags",
+			template: &Template{Tag: "topic", Template: `{{.Tag "host"}}-{{.Tag "interface"}}`},
+			expected: testutil.MustMetric("net",
+				map[string]string{"host": "db01", "interface": "eth0", "topic": "db01-eth0"},
+				map[string]interface{}{"bytes_sent": int64(42), "speed": int64(0)},
+				now),
+		},
+		{
+			name: "measurement with conditional",
+			template: &Template{Measurement: true,
+				Template: `{{.Name}}{{if eq (.Field "speed") 0}}_down{{end}}`},
+			expected: testutil.MustMetric("net_down",
+				map[string]string{"host": "db01", "interface": "eth0"},
+				map[string]interface{}{"bytes_sent": int64(42), "speed": int64(0)},
+				now),
+		},
+		{
+			name: "functions",
+			template: &Template{Tag: "host",
+				Template: `{{.Tag "host" | upper | trimSuffix "01"}}`},
+			expected: testutil.MustMetric("net",
+				map[string]string{"host": "DB", "interface": "eth0"},
+				map[string]interface{}{"bytes_sent": int64(42), "speed": int64(0)},
+				now),
+		},
+		{
+			name: "empty result unchanged",
+			template: &Template{Tag: "site",
+				Template: `{{if .HasTag "site"}}{{.Tag "site"}}{{end}}`},
+			expected: input(),
+		},
+		{
+			name:     "error unchanged",
+			template: &Template{Tag: "topic", Template: `{{.Field "bytes_sent" | lower}}`},
+			expected: input(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.template.Log = testutil.Logger{}
+			require.NoError(t, tt.template.Init())
+			actual := tt.template.Apply(input())
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	for _, tmpl := range []*Template{
+		{Template: `{{.Name}}`},
+		{Tag: "a", Measurement: true, Template: `{{.Name}}`},
+		{Tag: "a", Template: `{{.Name`},
+		{Tag: "a", Template: `{{unknown .Name}}`},
+	} {
+		require.Error(t, tmpl.Init())
+	}
+}
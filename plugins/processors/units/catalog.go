@@ -0,0 +1,109 @@
+package units
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// unit converts values to and from the base unit of its dimension:
+// base = value * factor + offset.
+type unit struct {
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+var (
+	siPrefixes = []struct {
+		prefix string
+		factor float64
+	}{
+		{"k", 1e3}, {"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15},
+	}
+	binaryPrefixes = []struct {
+		prefix string
+		factor float64
+	}{
+		{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50},
+	}
+)
+
+// catalog holds the units by name, built by newCatalog.
+var catalog = newCatalog()
+
+func newCatalog() map[string]unit {
+	c := make(map[string]unit)
+
+	// Data sizes in bytes, with bits, and data rates in bits per second.
+	for _, name := range []string{"B", "byte", "bytes"} {
+		c[name] = unit{dimension: "data", factor: 1}
+	}
+	for _, name := range []string{"b", "bit", "bits"} {
+		c[name] = unit{dimension: "data", factor: 1.0 / 8}
+	}
+	for _, name := range []string{"bps", "b/s", "bit/s"} {
+		c[name] = unit{dimension: "data rate", factor: 1}
+	}
+	for _, name := range []string{"Bps", "B/s"} {
+		c[name] = unit{dimension: "data rate", factor: 8}
+	}
+	for _, base := range []string{"B", "b", "bit", "bps", "b/s", "bit/s", "Bps", "B/s"} {
+		for _, p := range siPrefixes {
+			c[p.prefix+base] = unit{dimension: c[base].dimension, factor: c[base].factor * p.factor}
+		}
+		for _, p := range binaryPrefixes {
+			c[p.prefix+base] = unit{dimension: c[base].dimension, factor: c[base].factor * p.factor}
+		}
+	}
+
+	// Durations in seconds.
+	for name, factor := range map[string]float64{
+		"ns": 1e-9, "us": 1e-6, "µs": 1e-6, "ms": 1e-3, "s": 1, "sec": 1,
+		"min": 60, "h": 3600, "d": 86400,
+	} {
+		c[name] = unit{dimension: "time", factor: factor}
+	}
+
+	// Temperatures in kelvins.
+	for _, name := range []string{"K", "kelvin"} {
+		c[name] = unit{dimension: "temperature", factor: 1}
+	}
+	for _, name := range []string{"C", "°C", "celsius"} {
+		c[name] = unit{dimension: "temperature", factor: 1, offset: 273.15}
+	}
+	for _, name := range []string{"F", "°F", "fahrenheit"} {
+		c[name] = unit{dimension: "temperature", factor: 5.0 / 9, offset: 273.15 - 32*5.0/9}
+	}
+	return c
+}
+
+func lookupUnit(name string) (unit, error) {
+	u, ok := catalog[name]
+	if !ok {
+		return unit{}, fmt.Errorf("unknown unit %q", name)
+	}
+	return u, nil
+}
+
+// convert converts the value from a unit to another of the same dimension.
+func convert(v float64, from, to unit) float64 {
+	return (v*from.factor + from.offset - to.offset) / to.factor
+}
+
+var quantityRe = regexp.MustCompile(`^\s*([-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*(\S*)\s*$`)
+
+// parseQuantity returns the number and unit of a string such as "16GB" or
+// "1.5 ms", the unit is empty if there is none.
+func parseQuantity(s string) (float64, string, error) {
+	match := quantityRe.FindStringSubmatch(s)
+	if match == nil {
+		return 0, "", fmt.Errorf("invalid quantity %q", s)
+	}
+	v, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, "", err
+	}
+	return v, strings.TrimSpace(match[2]), nil
+}
@@ -0,0 +1,152 @@
+package units
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Fields converted to a unit, globs are supported.  The fields are either
+  ## numbers in the from unit, or strings of a number followed by a unit
+  ## such as "16GB" or "1.5 ms".
+  [[processors.units.convert]]
+    fields = ["memory"]
+    to = "GiB"
+
+    ## Unit of the numeric fields and of the strings without a unit.
+    # from = "B"
+
+    ## Tag added with the unit of the fields.
+    # unit_tag = "memory_unit"
+
+  # [[processors.units.convert]]
+  #   fields = ["speed"]
+  #   from = "Mb/s"
+  #   to = "b/s"
+`
+
+type Units struct {
+	Convert []*Conversion `toml:"convert"`
+
+	Log telegraf.Logger `toml:"-"`
+}
+
+// Conversion converts fields to a unit.
+type Conversion struct {
+	Fields  []string `toml:"fields"`
+	From    string   `toml:"from"`
+	To      string   `toml:"to"`
+	UnitTag string   `toml:"unit_tag"`
+
+	fields   filter.Filter
+	from, to unit
+}
+
+func (u *Units) SampleConfig() string {
+	return sampleConfig
+}
+
+func (u *Units) Description() string {
+	return "Convert fields to a unit, parsing strings with a unit"
+}
+
+func (u *Units) Init() error {
+	for _, c := range u.Convert {
+		if len(c.Fields) == 0 {
+			return fmt.Errorf("conversion to %s has no fields", c.To)
+		}
+		var err error
+		c.fields, err = filter.Compile(c.Fields)
+		if err != nil {
+			return err
+		}
+
+		c.to, err = lookupUnit(c.To)
+		if err != nil {
+			return err
+		}
+		if c.From != "" {
+			c.from, err = lookupUnit(c.From)
+			if err != nil {
+				return err
+			}
+			if c.from.dimension != c.to.dimension {
+				return fmt.Errorf("cannot convert %s to %s", c.From, c.To)
+			}
+		}
+	}
+	return nil
+}
+
+func (u *Units) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		for _, c := range u.Convert {
+			converted := make(map[string]interface{})
+			for _, f := range m.FieldList() {
+				if !c.fields.Match(f.Key) {
+					continue
+				}
+				v, err := c.convert(f.Value)
+				if err != nil {
+					u.Log.Errorf("Could not convert %s of %s: %v", f.Key, m.Name(), err)
+					continue
+				}
+				converted[f.Key] = v
+			}
+			for k, v := range converted {
+				m.AddField(k, v)
+			}
+			if len(converted) > 0 && c.UnitTag != "" {
+				m.AddTag(c.UnitTag, c.To)
+			}
+		}
+	}
+	return in
+}
+
+// convert returns the value converted to the unit as a float.
+func (c *Conversion) convert(value interface{}) (float64, error) {
+	var v float64
+	from, name := c.from, c.From
+	switch value := value.(type) {
+	case float64:
+		v = value
+	case int64:
+		v = float64(value)
+	case uint64:
+		v = float64(value)
+	case string:
+		var err error
+		v, name, err = parseQuantity(value)
+		if err != nil {
+			return 0, err
+		}
+		if name == "" {
+			name = c.From
+			break
+		}
+		from, err = lookupUnit(name)
+		if err != nil {
+			return 0, err
+		}
+		if from.dimension != c.to.dimension {
+			return 0, fmt.Errorf("cannot convert %s to %s", name, c.To)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported type %T", value)
+	}
+
+	if name == "" {
+		return 0, fmt.Errorf("%v has no unit", value)
+	}
+	return convert(v, from, c.to), nil
+}
+
+func init() {
+	processors.Add("units", func() telegraf.Processor {
+		return &Units{}
+	})
+}
@@ -0,0 +1,134 @@
+package units
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		from, to string
+		value    float64
+		expected float64
+	}{
+		{"B", "GiB", 16 * (1 << 30), 16},
+		{"GB", "B", 16, 16e9},
+		{"KiB", "MiB", 2048, 2},
+		{"Mb/s", "b/s", 100, 100e6},
+		{"Gbps", "MB/s", 1, 125},
+		{"b", "B", 64, 8},
+		{"ms", "s", 1500, 1.5},
+		{"min", "ms", 2, 120000},
+		{"µs", "ns", 3, 3000},
+		{"C", "F", 100, 212},
+		{"F", "C", 32, 0},
+		{"K", "°C", 0, -273.15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			from, err := lookupUnit(tt.from)
+			require.NoError(t, err)
+			to, err := lookupUnit(tt.to)
+			require.NoError(t, err)
+			require.Equal(t, from.dimension, to.dimension)
+			require.InDelta(t, tt.expected, convert(tt.value, from, to), 1e-9)
+		})
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		s     string
+		value float64
+		unit  string
+	}{
+		{"16GB", 16, "GB"},
+		{" 1.5 ms ", 1.5, "ms"},
+		{"-40°C", -40, "°C"},
+		{"1e3", 1000, ""},
+		{".5KiB", 0.5, "KiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			v, unit, err := parseQuantity(tt.s)
+			require.NoError(t, err)
+			require.Equal(t, tt.value, v)
+			require.Equal(t, tt.unit, unit)
+		})
+	}
+
+	for _, s := range []string{"", "GB", "16 G B", "unknown"} {
+		_, _, err := parseQuantity(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	u := &Units{
+		Convert: []*Conversion{
+			{Fields: []string{"memory*"}, To: "GiB", UnitTag: "memory_unit"},
+			{Fields: []string{"speed"}, From: "Mb/s", To: "b/s"},
+			{Fields: []string{"average_response_ms"}, From: "ms", To: "s"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, u.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("sm4p_systeminfo",
+			map[string]string{},
+			map[string]interface{}{"memory": "16GiB", "memory_free": "512 MiB", "os": "linux"},
+			now),
+		testutil.MustMetric("smnet",
+			map[string]string{},
+			map[string]interface{}{"speed": int64(1000)},
+			now),
+		testutil.MustMetric("ping",
+			map[string]string{},
+			map[string]interface{}{"average_response_ms": 12.5},
+			now),
+		// Values that cannot be converted are unchanged.
+		testutil.MustMetric("sm4p_systeminfo",
+			map[string]string{},
+			map[string]interface{}{"memory": "16", "memory_free": "1 ms"},
+			now),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("sm4p_systeminfo",
+			map[string]string{"memory_unit": "GiB"},
+			map[string]interface{}{"memory": 16.0, "memory_free": 0.5, "os": "linux"},
+			now),
+		testutil.MustMetric("smnet",
+			map[string]string{},
+			map[string]interface{}{"speed": 1e9},
+			now),
+		testutil.MustMetric("ping",
+			map[string]string{},
+			map[string]interface{}{"average_response_ms": 0.0125},
+			now),
+		testutil.MustMetric("sm4p_systeminfo",
+			map[string]string{},
+			map[string]interface{}{"memory": "16", "memory_free": "1 ms"},
+			now),
+	}
+
+	actual := u.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInitErrors(t *testing.T) {
+	for _, c := range []*Conversion{
+		{To: "GiB"},
+		{Fields: []string{"a"}, To: "parsec"},
+		{Fields: []string{"a"}, From: "gallon", To: "GiB"},
+		{Fields: []string{"a"}, From: "ms", To: "GiB"},
+	} {
+		u := &Units{Convert: []*Conversion{c}}
+		require.Error(t, u.Init())
+	}
+}
@@ -10,3 +10,16 @@ type Processor interface {
 	// Apply the filter to the given metric.
 	Apply(in ...Metric) []Metric
 }
+
+// StreamingProcessor is a Processor running in the background between Start
+// and Stop, such as a processor exchanging metrics with an external program.
+// Apply returns the metrics processed synchronously, the metrics processed
+// asynchronously are added to the accumulator given to Start.
+type StreamingProcessor interface {
+	Processor
+
+	// Start the processor, adding the processed metrics to acc.
+	Start(acc Accumulator) error
+	// Stop the processor, after adding the metrics being processed.
+	Stop()
+}
@@ -0,0 +1,152 @@
+package selfstat
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+const (
+	// histogramSize is the maximum number of samples kept between calls to
+	// Get, further samples replace random samples already kept.
+	histogramSize = 1024
+)
+
+// histogramQuantiles are the quantiles reported by histogram stats as the
+// fields "<field>_p50", "<field>_p90", "<field>_p99".
+var histogramQuantiles = []struct {
+	suffix   string
+	quantile float64
+}{
+	{"_p50", 0.50},
+	{"_p90", 0.90},
+	{"_p99", 0.99},
+}
+
+type histogramStat struct {
+	measurement string
+	field       string
+	tags        map[string]string
+	samples     []int64
+	count       int64
+	prev        map[string]interface{}
+	mu          sync.Mutex
+}
+
+func (s *histogramStat) Incr(v int64) {
+	s.mu.Lock()
+	s.count++
+	if len(s.samples) < histogramSize {
+		s.samples = append(s.samples, v)
+	} else if i := rand.Int63n(s.count); i < histogramSize {
+		s.samples[i] = v
+	}
+	s.mu.Unlock()
+}
+
+func (s *histogramStat) Set(v int64) {
+	s.Incr(v)
+}
+
+// Get returns the average of the samples received since the previous call to
+// Get or Fields.
+func (s *histogramStat) Get() int64 {
+	return s.Fields()[s.field].(int64)
+}
+
+// Fields returns the average, quantiles and maximum of the samples received
+// since the previous call to Get or Fields.  If no samples were received, it
+// returns the previous values.
+func (s *histogramStat) Fields() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) > 0 {
+		sort.Slice(s.samples, func(i, j int) bool { return s.samples[i] < s.samples[j] })
+		s.prev = s.summarize(s.samples)
+		s.samples = s.samples[:0]
+		s.count = 0
+	}
+	return s.previous()
+}
+
+// peekFields returns the fields Fields would return without clearing the
+// samples.
+func (s *histogramStat) peekFields() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) > 0 {
+		sorted := append([]int64(nil), s.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		return s.summarize(sorted)
+	}
+	return s.previous()
+}
+
+// summarize returns the fields of the sorted samples.
+func (s *histogramStat) summarize(sorted []int64) map[string]interface{} {
+	var sum int64
+	for _, v := range sorted {
+		sum += v
+	}
+
+	fields := map[string]interface{}{
+		s.field:          sum / int64(len(sorted)),
+		s.field + "_max": sorted[len(sorted)-1],
+	}
+	for _, q := range histogramQuantiles {
+		fields[s.field+q.suffix] = quantile(sorted, q.quantile)
+	}
+	return fields
+}
+
+// previous returns a copy of the fields of the previous samples, zero if
+// there were none.
+func (s *histogramStat) previous() map[string]interface{} {
+	if s.prev == nil {
+		s.prev = map[string]interface{}{
+			s.field:          int64(0),
+			s.field + "_max": int64(0),
+		}
+		for _, q := range histogramQuantiles {
+			s.prev[s.field+q.suffix] = int64(0)
+		}
+	}
+
+	fields := make(map[string]interface{}, len(s.prev))
+	for k, v := range s.prev {
+		fields[k] = v
+	}
+	return fields
+}
+
+// quantile returns the nearest-rank quantile of the sorted samples.
+func quantile(sorted []int64, q float64) int64 {
+	i := int(q*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+func (s *histogramStat) Name() string {
+	return s.measurement
+}
+
+func (s *histogramStat) FieldName() string {
+	return s.field
+}
+
+// Tags returns a copy of the histogramStat's tags.
+// NOTE this allocates a new map every time it is called.
+func (s *histogramStat) Tags() map[string]string {
+	m := make(map[string]string, len(s.tags))
+	for k, v := range s.tags {
+		m[k] = v
+	}
+	return m
+}
@@ -76,6 +76,31 @@ func RegisterTiming(measurement, field string, tags map[string]string) Stat {
 	return registry.registerTiming("internal_"+measurement, field, tags)
 }
 
+// RegisterHistogram registers the given measurement, field, and tags in the
+// selfstat registry. If given an identical measurement, it will return the stat
+// that's already been registered.
+//
+// Histogram stats accumulate multiple timings like timing stats, in addition
+// to the average in the field they report the 50th, 90th and 99th percentile
+// and the maximum of the timings in the "<field>_p50", "<field>_p90",
+// "<field>_p99" and "<field>_max" fields.  The values are cleared after each
+// call to Get() or Metrics().
+//
+// The returned Stat can be incremented by the consumer of Register(), and it's
+// value will be returned as a telegraf metric when Metrics() is called.
+func RegisterHistogram(measurement, field string, tags map[string]string) Stat {
+	return registry.registerHistogram("internal_"+measurement, field, tags)
+}
+
+// Unregister removes the stats of the given measurement and tags from the
+// selfstat registry, for stats of short-lived entities.  The removed stats
+// can still be used but are no longer returned by Metrics().
+func Unregister(measurement string, tags map[string]string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	delete(registry.stats, key("internal_"+measurement, tags))
+}
+
 // Metrics returns all registered stats as telegraf metrics.
 func Metrics() []telegraf.Metric {
 	registry.mu.Lock()
@@ -93,7 +118,13 @@ func Metrics() []telegraf.Metric {
 					tags = stat.Tags()
 					name = stat.Name()
 				}
-				fields[fieldname] = stat.Get()
+				if h, ok := stat.(*histogramStat); ok {
+					for k, v := range h.Fields() {
+						fields[k] = v
+					}
+				} else {
+					fields[fieldname] = stat.Get()
+				}
 				j++
 			}
 			metric, err := metric.New(name, tags, fields, now)
@@ -109,6 +140,47 @@ func Metrics() []telegraf.Metric {
 	return metrics
 }
 
+// Snapshot returns the registered stats as telegraf metrics like Metrics, but
+// without clearing the timing and histogram stats, so that taking a snapshot
+// does not take samples away from the values later returned by Metrics.
+func Snapshot() []telegraf.Metric {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0, len(registry.stats))
+	for _, stats := range registry.stats {
+		var tags map[string]string
+		var name string
+		fields := map[string]interface{}{}
+		for fieldname, stat := range stats {
+			tags = stat.Tags()
+			name = stat.Name()
+			switch stat := stat.(type) {
+			case *timingStat:
+				fields[fieldname] = stat.peek()
+			case *histogramStat:
+				for k, v := range stat.peekFields() {
+					fields[k] = v
+				}
+			default:
+				fields[fieldname] = stat.Get()
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		metric, err := metric.New(name, tags, fields, now)
+		if err != nil {
+			log.Printf("E! Error creating selfstat metric: %s", err)
+			continue
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
 type rgstry struct {
 	stats map[uint64]map[string]Stat
 	mu    sync.Mutex
@@ -160,6 +232,29 @@ func (r *rgstry) registerTiming(measurement, field string, tags map[string]strin
 	return s
 }
 
+func (r *rgstry) registerHistogram(measurement, field string, tags map[string]string) Stat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := key(measurement, tags)
+	if stat, ok := registry.get(key, field); ok {
+		return stat
+	}
+
+	t := make(map[string]string, len(tags))
+	for k, v := range tags {
+		t[k] = v
+	}
+
+	s := &histogramStat{
+		measurement: measurement,
+		field:       field,
+		tags:        t,
+	}
+	registry.set(key, s)
+	return s
+}
+
 func (r *rgstry) get(key uint64, field string) (Stat, bool) {
 	if _, ok := r.stats[key]; !ok {
 		return nil, false
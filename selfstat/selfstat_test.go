@@ -3,7 +3,9 @@ package selfstat
 import (
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -108,6 +110,121 @@ func TestRegisterTimingAndIncrAndSet(t *testing.T) {
 	assert.Equal(t, "internal_test", foo.Name())
 }
 
+func TestRegisterHistogramAndIncr(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+	s1 := RegisterHistogram("test", "test_field1_ns", map[string]string{"test": "foo"})
+	h := s1.(*histogramStat)
+
+	zero := map[string]interface{}{
+		"test_field1_ns":     int64(0),
+		"test_field1_ns_p50": int64(0),
+		"test_field1_ns_p90": int64(0),
+		"test_field1_ns_p99": int64(0),
+		"test_field1_ns_max": int64(0),
+	}
+	assert.Equal(t, zero, h.Fields())
+
+	for i := int64(1); i <= 100; i++ {
+		s1.Incr(i)
+	}
+	expected := map[string]interface{}{
+		"test_field1_ns":     int64(50),
+		"test_field1_ns_p50": int64(50),
+		"test_field1_ns_p90": int64(90),
+		"test_field1_ns_p99": int64(99),
+		"test_field1_ns_max": int64(100),
+	}
+	assert.Equal(t, expected, h.Fields())
+	// previous values are used on subsequent calls
+	assert.Equal(t, expected, h.Fields())
+
+	s1.Set(7)
+	assert.Equal(t, int64(7), s1.Get())
+
+	// make sure that the same field returns the same metric
+	foo := RegisterHistogram("test", "test_field1_ns", map[string]string{"test": "foo"})
+	assert.Equal(t, s1, foo)
+
+	s1.Incr(3)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			testutil.MustMetric(
+				"internal_test",
+				map[string]string{"test": "foo"},
+				map[string]interface{}{
+					"test_field1_ns":     int64(3),
+					"test_field1_ns_p50": int64(3),
+					"test_field1_ns_p90": int64(3),
+					"test_field1_ns_p99": int64(3),
+					"test_field1_ns_max": int64(3),
+				},
+				time.Unix(0, 0),
+			),
+		},
+		Metrics(),
+		testutil.IgnoreTime())
+}
+
+func TestHistogramSampleLimit(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+	s1 := RegisterHistogram("test", "test_field1_ns", map[string]string{"test": "foo"})
+	h := s1.(*histogramStat)
+
+	for i := 0; i < 10*histogramSize; i++ {
+		s1.Incr(42)
+	}
+	assert.Len(t, h.samples, histogramSize)
+	assert.Equal(t, int64(42), h.Fields()["test_field1_ns_p99"])
+	assert.Len(t, h.samples, 0)
+}
+
+func TestSnapshot(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+	s1 := Register("test", "test_field1", map[string]string{"test": "foo"})
+	s2 := RegisterHistogram("test", "test_field2_ns", map[string]string{"test": "foo"})
+	s3 := RegisterTiming("test", "test_field3_ns", map[string]string{"test": "bar"})
+
+	s1.Incr(10)
+	s2.Incr(100)
+	s3.Incr(100)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			testutil.MustMetric(
+				"internal_test",
+				map[string]string{"test": "foo"},
+				map[string]interface{}{
+					"test_field1":        int64(10),
+					"test_field2_ns":     int64(100),
+					"test_field2_ns_max": int64(100),
+					"test_field2_ns_p50": int64(100),
+					"test_field2_ns_p90": int64(100),
+					"test_field2_ns_p99": int64(100),
+				},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"internal_test",
+				map[string]string{"test": "bar"},
+				map[string]interface{}{
+					"test_field3_ns": int64(100),
+				},
+				time.Unix(0, 0),
+			),
+		},
+		Snapshot(),
+		testutil.SortMetrics(),
+		testutil.IgnoreTime())
+
+	// the snapshot does not clear the timings
+	s2.Incr(300)
+	s3.Incr(300)
+	assert.Equal(t, int64(200), s2.Get())
+	assert.Equal(t, int64(200), s3.Get())
+}
+
 func TestStatKeyConsistency(t *testing.T) {
 	lhs := key("internal_stats", map[string]string{
 		"foo":   "bar",
@@ -211,3 +328,23 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestUnregister(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+	Register("test", "test_field1", map[string]string{"test": "foo"}).Set(1)
+	Register("test", "test_field2", map[string]string{"test": "foo"}).Set(2)
+	Register("test", "test_field1", map[string]string{"test": "bar"}).Set(3)
+
+	Unregister("test", map[string]string{"test": "foo"})
+	var tags []map[string]string
+	for _, m := range Metrics() {
+		if m.Name() == "internal_test" {
+			tags = append(tags, m.Tags())
+		}
+	}
+	require.Equal(t, []map[string]string{{"test": "bar"}}, tags)
+
+	// Registering again starts from zero.
+	require.Equal(t, int64(0), Register("test", "test_field1", map[string]string{"test": "foo"}).Get())
+}
@@ -0,0 +1,48 @@
+package testutil
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+type deliveryInfo struct {
+	id        telegraf.TrackingID
+	delivered bool
+}
+
+func (d deliveryInfo) ID() telegraf.TrackingID { return d.id }
+func (d deliveryInfo) Delivered() bool         { return d.delivered }
+
+// TrackingAccumulator reports the delivery of each tracked metric group as
+// soon as it is added, successful if Success is set, unless Pending is set.
+type TrackingAccumulator struct {
+	Accumulator
+	Success bool
+	Pending bool
+
+	id        telegraf.TrackingID
+	delivered chan telegraf.DeliveryInfo
+}
+
+func (a *TrackingAccumulator) WithTracking(maxTracked int) telegraf.TrackingAccumulator {
+	a.delivered = make(chan telegraf.DeliveryInfo, maxTracked)
+	return a
+}
+
+func (a *TrackingAccumulator) AddTrackingMetric(m telegraf.Metric) telegraf.TrackingID {
+	return a.AddTrackingMetricGroup([]telegraf.Metric{m})
+}
+
+func (a *TrackingAccumulator) AddTrackingMetricGroup(group []telegraf.Metric) telegraf.TrackingID {
+	for _, m := range group {
+		a.AddMetric(m)
+	}
+	a.id++
+	if !a.Pending {
+		a.delivered <- deliveryInfo{id: a.id, delivered: a.Success}
+	}
+	return a.id
+}
+
+func (a *TrackingAccumulator) Delivered() <-chan telegraf.DeliveryInfo {
+	return a.delivered
+}